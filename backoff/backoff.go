@@ -71,6 +71,22 @@ func (b *Backoff) Attempt(now time.Time) time.Duration {
 	return retryAfter
 }
 
+// AttemptAfter is like Attempt but waits at least retryAfter, such as the
+// delay suggested by a rate limiter or a Retry-After header.
+func (b *Backoff) AttemptAfter(now time.Time, retryAfter time.Duration) time.Duration {
+	delay := b.Attempt(now)
+	if retryAfter > delay {
+		b.retryAt = now.Add(retryAfter)
+		return retryAfter
+	}
+
+	return delay
+}
+
+func (b *Backoff) WaitAfter(retryAfter time.Duration) <-chan time.Time {
+	return time.After(b.AttemptAfter(time.Now(), retryAfter))
+}
+
 func (b *Backoff) Wait() <-chan time.Time {
 	return b.wait(time.Now())
 }
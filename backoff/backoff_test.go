@@ -46,6 +46,21 @@ func TestReset(t *testing.T) {
 	assert.Equal(t, 1*time.Second, b.Attempt(now))
 }
 
+func TestAttemptAfter(t *testing.T) {
+	t.Parallel()
+
+	b := New(0, 0, 120, false)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 30*time.Second, b.AttemptAfter(now, 30*time.Second))
+
+	now = now.Add(10 * time.Second) // before retry after
+	assert.Equal(t, 20*time.Second, b.Attempt(now))
+
+	now = now.Add(20 * time.Second)
+	assert.Equal(t, 4*time.Second, b.AttemptAfter(now, 2*time.Second))
+}
+
 func TestAttemptConstrained(t *testing.T) {
 	t.Parallel()
 
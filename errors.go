@@ -3,3 +3,4 @@ package common
 import "errors"
 
 var ErrNotFound = errors.New("not found")
+var ErrStaleVersion = errors.New("stale version")
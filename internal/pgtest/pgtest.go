@@ -0,0 +1,84 @@
+package pgtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common/env"
+	"github.com/stretchr/testify/require"
+)
+
+// Pool creates a uniquely named database, applies the migrations and returns
+// a pool connected to it. The database is dropped by `make test`.
+func Pool(t *testing.T, migrations ...string) *pgxpool.Pool {
+	// Parse the database url.
+	url, err := url.Parse(env.Required("DATABASE_URL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create the database.
+	dbName := "test-" + uuid.NewString()
+	exitCode := createDatabase(t, url, dbName)
+	require.Equal(t, 0, exitCode)
+
+	// Replace the path with a unique database name.
+	url.Path = "/" + dbName
+
+	// Create a new db.
+	dbPool, err := pgxpool.New(context.Background(), url.String())
+	if err != nil {
+		t.Fatalf("Unable to create connection pool: %v", err)
+	}
+
+	// Migrate the database.
+	ctx := context.Background()
+	for _, migration := range migrations {
+		if _, err := dbPool.Exec(ctx, migration); err != nil {
+			dbPool.Close()
+			t.Fatalf("Unable to migrate database: %v", err)
+		}
+	}
+
+	// Success.
+	return dbPool
+}
+
+func createDatabase(t *testing.T, url *url.URL, dbName string) int {
+	// Execute psql command to create the database.
+	cmd := exec.Command("psql", url.String(), "-c", fmt.Sprintf(`CREATE DATABASE "%s";`, dbName))
+	if errors.Is(cmd.Err, exec.ErrDot) {
+		cmd.Err = nil
+	}
+
+	writer := &testWriter{t: t}
+	cmd.Stderr = writer
+	cmd.Stdout = writer
+
+	// Set the working dir and run.
+	cmd.Dir = "/workspace"
+	if err := cmd.Run(); err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return exitError.ExitCode()
+		}
+
+		t.Fatal(err)
+	}
+
+	return 0
+}
+
+type testWriter struct {
+	t *testing.T
+}
+
+func (w *testWriter) Write(p []byte) (n int, err error) {
+	w.t.Log(string(p))
+	return len(p), nil
+}
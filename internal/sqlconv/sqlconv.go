@@ -0,0 +1,72 @@
+package sqlconv
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+)
+
+// Assign stores a non-nil value produced by a database driver in dst, which
+// must be a pointer. Scanners are delegated to and otherwise the value is
+// assigned or converted between compatible kinds, checking for overflow.
+func Assign(dst any, src any) error {
+	if scanner, ok := dst.(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() {
+		return fmt.Errorf("cannot scan into %T", dst)
+	}
+
+	dv = dv.Elem()
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dv.Type()) {
+		dv.Set(sv)
+		return nil
+	}
+
+	switch dv.Kind() {
+	case reflect.String:
+		switch src := src.(type) {
+		case string:
+			dv.SetString(src)
+			return nil
+		case []byte:
+			dv.SetString(string(src))
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if src, ok := src.(int64); ok && !dv.OverflowInt(src) {
+			dv.SetInt(src)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if src, ok := src.(int64); ok && src >= 0 && !dv.OverflowUint(uint64(src)) {
+			dv.SetUint(uint64(src))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch src := src.(type) {
+		case float64:
+			dv.SetFloat(src)
+			return nil
+		case int64:
+			dv.SetFloat(float64(src))
+			return nil
+		}
+	case reflect.Bool:
+		if src, ok := src.(bool); ok {
+			dv.SetBool(src)
+			return nil
+		}
+	}
+
+	// Named types with the same underlying kind, such as time.Time.
+	if sv.Kind() == dv.Kind() && sv.Type().ConvertibleTo(dv.Type()) {
+		dv.Set(sv.Convert(dv.Type()))
+		return nil
+	}
+
+	return fmt.Errorf("cannot scan %T into %T", src, dst)
+}
@@ -0,0 +1,78 @@
+package sqlconv
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type name string
+
+func TestAssign(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	id := uuid.New()
+
+	tests := []struct {
+		name     string
+		dst      any
+		src      any
+		expected any
+	}{
+		{"string", new(string), "a", "a"},
+		{"string from bytes", new(string), []byte("a"), "a"},
+		{"named string", new(name), "a", name("a")},
+		{"int", new(int), int64(1), 1},
+		{"int32", new(int32), int64(1), int32(1)},
+		{"uint16", new(uint16), int64(1), uint16(1)},
+		{"float32", new(float32), float64(1.5), float32(1.5)},
+		{"float64 from int", new(float64), int64(2), float64(2)},
+		{"bool", new(bool), true, true},
+		{"time", new(time.Time), now, now},
+		{"scanner", new(uuid.UUID), id.String(), id},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.NoError(t, Assign(tt.dst, tt.src))
+			assert.Equal(t, tt.expected, deref(tt.dst))
+		})
+	}
+}
+
+func TestAssignErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		dst  any
+		src  any
+	}{
+		{"not a pointer", "", "a"},
+		{"int to string", new(string), int64(1)},
+		{"string to int", new(int), "1"},
+		{"overflow", new(int8), int64(math.MaxInt16)},
+		{"negative uint", new(uint), int64(-1)},
+		{"bool to int", new(int), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Error(t, Assign(tt.dst, tt.src))
+		})
+	}
+}
+
+func deref(v any) any {
+	return reflect.ValueOf(v).Elem().Interface()
+}
@@ -2,7 +2,9 @@ package nilable
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 )
 
 type Map[K comparable, V any] struct {
@@ -64,3 +66,43 @@ func (m *Map[K, V]) UnmarshalJSON(b []byte) error {
 	m.Reset()
 	return nil
 }
+
+// Scan implements sql.Scanner for json and jsonb columns. NULL is scanned as
+// a valid nil map.
+func (m *Map[K, V]) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		*m = NilMap[K, V]()
+		return nil
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, m)
+	}
+
+	var val map[K]V
+	if err := json.Unmarshal(data, &val); err != nil {
+		return err
+	}
+
+	*m = NewMap(val)
+	return nil
+}
+
+// Value implements driver.Valuer, encoding the map as JSON. Nil and invalid
+// maps are encoded as NULL.
+func (m Map[K, V]) Value() (driver.Value, error) {
+	if m.OrNil() == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(m.Map)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestMap(t *testing.T) {
@@ -81,3 +82,31 @@ func TestMapOrNil(t *testing.T) {
 	assert.Nil(t, InvalidMap[int, int]().OrNil())
 	assert.Equal(t, map[int]int{1: 2}, NewMap(map[int]int{1: 2}).OrNil())
 }
+
+func TestMapScan(t *testing.T) {
+	t.Parallel()
+
+	var m Map[string, int]
+	require.NoError(t, m.Scan(`{"a":1}`))
+	assert.Equal(t, NewMap(map[string]int{"a": 1}), m)
+
+	require.NoError(t, m.Scan([]byte(`{"b":2}`)))
+	assert.Equal(t, NewMap(map[string]int{"b": 2}), m)
+
+	require.NoError(t, m.Scan(nil))
+	assert.Equal(t, NilMap[string, int](), m)
+
+	assert.Error(t, m.Scan(int64(1)))
+}
+
+func TestMapValue(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewMap(map[string]int{"a": 1}).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, v)
+
+	v, err = NilMap[string, int]().Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
@@ -2,6 +2,7 @@ package nilable
 
 import (
 	"bytes"
+	"database/sql/driver"
 	"encoding/json"
 )
 
@@ -64,3 +65,14 @@ func (s *Slice[T]) UnmarshalJSON(b []byte) error {
 	s.Reset()
 	return nil
 }
+
+// Value implements driver.Valuer. Nil and invalid slices are encoded as NULL.
+// Slice does not implement sql.Scanner; scan Postgres arrays into a
+// postgres.NullableArray and convert it to a Slice instead.
+func (s Slice[T]) Value() (driver.Value, error) {
+	if s.OrNil() == nil {
+		return nil, nil
+	}
+
+	return s.Slice, nil
+}
@@ -81,3 +81,19 @@ func TestSliceOrNil(t *testing.T) {
 	assert.Nil(t, InvalidSlice[int]().OrNil())
 	assert.Equal(t, []int{1, 2}, NewSlice([]int{1, 2}).OrNil())
 }
+
+func TestSliceValue(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewSlice([]string{"a"}).Value()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a"}, v)
+
+	v, err = NilSlice[string]().Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+
+	v, err = InvalidSlice[string]().Value()
+	assert.NoError(t, err)
+	assert.Nil(t, v)
+}
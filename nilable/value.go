@@ -3,6 +3,8 @@ package nilable
 import (
 	"bytes"
 	"encoding/json"
+
+	"github.com/jeremybower/go-common/internal/sqlconv"
 )
 
 type Value[T any] struct {
@@ -63,3 +65,20 @@ func (v *Value[T]) UnmarshalJSON(b []byte) error {
 	v.Reset()
 	return nil
 }
+
+// Scan implements sql.Scanner so that a nullable column can be scanned
+// directly. NULL is scanned as a valid nil value.
+func (v *Value[T]) Scan(src any) error {
+	if src == nil {
+		*v = NilValue[T]()
+		return nil
+	}
+
+	var val T
+	if err := sqlconv.Assign(&val, src); err != nil {
+		return err
+	}
+
+	*v = NewValue(&val)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValue(t *testing.T) {
@@ -91,3 +92,19 @@ func TestValueOrNil(t *testing.T) {
 	assert.Nil(t, InvalidValue[string]().OrNil())
 	assert.Equal(t, &str, NewValue(&str).OrNil())
 }
+
+func TestValueScan(t *testing.T) {
+	t.Parallel()
+
+	var v Value[string]
+	require.NoError(t, v.Scan("test"))
+	assert.Equal(t, "test", *v.Value)
+
+	require.NoError(t, v.Scan(nil))
+	assert.Equal(t, NilValue[string](), v)
+
+	var n Value[int32]
+	require.NoError(t, n.Scan(int64(1)))
+	assert.Equal(t, int32(1), *n.Value)
+	assert.Error(t, n.Scan("one"))
+}
@@ -3,6 +3,8 @@ package optional
 import (
 	"bytes"
 	"encoding/json"
+
+	"github.com/jeremybower/go-common/internal/sqlconv"
 )
 
 type Value[T any] struct {
@@ -59,3 +61,19 @@ func (v *Value[T]) UnmarshalJSON(b []byte) error {
 	v.Reset()
 	return nil
 }
+
+// Scan implements sql.Scanner. Optional values cannot be null, so NULL is
+// returned as ErrUnexpectedNull.
+func (v *Value[T]) Scan(src any) error {
+	if src == nil {
+		return ErrUnexpectedNull
+	}
+
+	var val T
+	if err := sqlconv.Assign(&val, src); err != nil {
+		return err
+	}
+
+	*v = NewValue(val)
+	return nil
+}
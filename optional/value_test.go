@@ -5,6 +5,7 @@ import (
 
 	. "github.com/jeremybower/go-common/ext"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestValue(t *testing.T) {
@@ -88,3 +89,14 @@ func TestValueOrNil(t *testing.T) {
 	assert.Nil(t, InvalidValue[int]().OrNil())
 	assert.Equal(t, Ptr(42), NewValue(42).OrNil())
 }
+
+func TestValueScan(t *testing.T) {
+	t.Parallel()
+
+	var v Value[string]
+	require.NoError(t, v.Scan("test"))
+	assert.Equal(t, NewValue("test"), v)
+
+	assert.ErrorIs(t, v.Scan(nil), ErrUnexpectedNull)
+	assert.Error(t, v.Scan(int64(1)))
+}
@@ -0,0 +1,142 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jeremybower/go-common/pagination"
+	"github.com/jeremybower/go-common/postgres"
+)
+
+var ErrAudit = errors.New("postgres audit")
+var ErrNoTables = fmt.Errorf("%w: no tables", ErrAudit)
+
+// ActorSetting is the setting that the trigger records as the actor of a
+// change. Set it for a transaction with SetActor.
+const ActorSetting = "app.actor_id"
+
+// Migration creates the audit_log table and the trigger function that Install
+// attaches to tables.
+const Migration = `
+CREATE TABLE IF NOT EXISTS audit_log (
+	id BIGSERIAL PRIMARY KEY,
+	table_name TEXT NOT NULL,
+	row_key TEXT,
+	operation TEXT NOT NULL,
+	old_row JSONB,
+	new_row JSONB,
+	actor TEXT,
+	changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_row_idx ON audit_log (table_name, row_key, id);
+
+CREATE OR REPLACE FUNCTION audit_row_change() RETURNS trigger AS $$
+DECLARE
+	old_row JSONB;
+	new_row JSONB;
+BEGIN
+	IF TG_OP <> 'INSERT' THEN
+		old_row := to_jsonb(OLD);
+	END IF;
+
+	IF TG_OP <> 'DELETE' THEN
+		new_row := to_jsonb(NEW);
+	END IF;
+
+	INSERT INTO audit_log (table_name, row_key, operation, old_row, new_row, actor)
+	VALUES (
+		TG_TABLE_NAME,
+		COALESCE(new_row, old_row) ->> TG_ARGV[0],
+		TG_OP,
+		old_row,
+		new_row,
+		NULLIF(current_setting('` + ActorSetting + `', true), '')
+	);
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+`
+
+type Entry struct {
+	ID        int64           `db:"id"`
+	Table     string          `db:"table_name"`
+	Key       *string         `db:"row_key"`
+	Operation string          `db:"operation"`
+	OldRow    json.RawMessage `db:"old_row"`
+	NewRow    json.RawMessage `db:"new_row"`
+	Actor     *string         `db:"actor"`
+	ChangedAt time.Time       `db:"changed_at"`
+}
+
+// Install attaches the audit trigger to the tables, recording keyColumn as
+// the key of each changed row. It is safe to call again, such as from every
+// deploy.
+func Install(ctx context.Context, querier postgres.Querier, keyColumn string, tables ...string) error {
+	if len(tables) == 0 {
+		return ErrNoTables
+	}
+
+	for _, table := range tables {
+		sql := "CREATE OR REPLACE TRIGGER audit AFTER INSERT OR UPDATE OR DELETE ON " +
+			pgx.Identifier(strings.Split(table, ".")).Sanitize() +
+			" FOR EACH ROW EXECUTE FUNCTION audit_row_change('" + strings.ReplaceAll(keyColumn, "'", "''") + "')"
+		if _, err := postgres.Exec(ctx, querier, sql); err != nil {
+			return err
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// Uninstall detaches the audit trigger from the tables. Their history is
+// kept.
+func Uninstall(ctx context.Context, querier postgres.Querier, tables ...string) error {
+	for _, table := range tables {
+		sql := "DROP TRIGGER IF EXISTS audit ON " + pgx.Identifier(strings.Split(table, ".")).Sanitize()
+		if _, err := postgres.Exec(ctx, querier, sql); err != nil {
+			return err
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+// SetActor records the actor of the changes made by the rest of the
+// transaction.
+func SetActor(ctx context.Context, tx pgx.Tx, actor string) error {
+	_, err := postgres.Exec(ctx, tx, "SELECT set_config($1, $2, true)", ActorSetting, actor)
+	return err
+}
+
+var historyTemplate = postgres.MustParseNamed("audit.history", `
+	SELECT {{ if counting }} COUNT(*) {{ else }} * {{ end }}
+	FROM audit_log
+	WHERE table_name = {{ arg .Table }} AND row_key = {{ arg .Key }}
+	{{ if not counting }} ORDER BY id DESC LIMIT {{ pageSize }} OFFSET {{ firstItemIndex }} {{ end }}`)
+
+// History lists the changes to a row, most recent first. The table is named
+// without its schema.
+func History(
+	ctx context.Context,
+	querier postgres.Querier,
+	table string,
+	key string,
+	pageIndex int64,
+	pageSize int64,
+	minimumPageSize int64,
+	defaultPageSize int64,
+	maximumPageSize int64,
+) (*pagination.Result[*Entry], error) {
+	data := map[string]any{"Table": table, "Key": key}
+	return postgres.ListT[Entry](ctx, querier, historyTemplate, data,
+		pageIndex, pageSize, minimumPageSize, defaultPageSize, maximumPageSize)
+}
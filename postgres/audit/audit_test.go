@@ -0,0 +1,96 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common/internal/pgtest"
+	"github.com/jeremybower/go-common/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func databasePoolForTesting(t *testing.T) *pgxpool.Pool {
+	return pgtest.Pool(t,
+		"CREATE TABLE values (id BIGSERIAL PRIMARY KEY, name TEXT NOT NULL, value TEXT NOT NULL);",
+		Migration,
+	)
+}
+
+func TestInstallNoTables(t *testing.T) {
+	t.Parallel()
+
+	err := Install(context.Background(), nil, "id")
+	assert.ErrorIs(t, err, ErrNoTables)
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	require.NoError(t, Install(ctx, dbPool, "id", "values"))
+	require.NoError(t, Install(ctx, dbPool, "id", "public.values"))
+
+	// Change a row as an actor.
+	var id int64
+	err := postgres.WithTx(ctx, dbPool, func(tx pgx.Tx) error {
+		if err := SetActor(ctx, tx, "alice"); err != nil {
+			return err
+		}
+
+		row, err := postgres.ReadOne[int64](ctx, tx, "INSERT INTO values (name, value) VALUES ('a', 'inserted') RETURNING id")
+		if err != nil {
+			return err
+		}
+		id = *row
+
+		_, err = postgres.Exec(ctx, tx, "UPDATE values SET value = 'updated' WHERE id = $1", id)
+		return err
+	})
+	require.NoError(t, err)
+
+	// Change it without an actor.
+	_, err = postgres.Exec(ctx, dbPool, "DELETE FROM values WHERE id = $1", id)
+	require.NoError(t, err)
+
+	key := strconv.FormatInt(id, 10)
+	page, err := History(ctx, dbPool, "values", key, 0, 2, 1, 10, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.TotalItems)
+	assert.Equal(t, int64(2), page.TotalPages)
+	require.Len(t, page.Items, 2)
+
+	deleted := page.Items[0]
+	assert.Equal(t, "DELETE", deleted.Operation)
+	assert.Nil(t, deleted.Actor)
+	assert.JSONEq(t, `{"id": `+key+`, "name": "a", "value": "updated"}`, string(deleted.OldRow))
+	assert.Nil(t, deleted.NewRow)
+
+	updated := page.Items[1]
+	assert.Equal(t, "UPDATE", updated.Operation)
+	require.NotNil(t, updated.Actor)
+	assert.Equal(t, "alice", *updated.Actor)
+	assert.JSONEq(t, `{"id": `+key+`, "name": "a", "value": "inserted"}`, string(updated.OldRow))
+	assert.JSONEq(t, `{"id": `+key+`, "name": "a", "value": "updated"}`, string(updated.NewRow))
+
+	page, err = History(ctx, dbPool, "values", key, 1, 2, 1, 10, 100)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "INSERT", page.Items[0].Operation)
+	assert.Equal(t, "values", page.Items[0].Table)
+
+	// Changes are no longer recorded once uninstalled.
+	require.NoError(t, Uninstall(ctx, dbPool, "values"))
+	_, err = postgres.Exec(ctx, dbPool, "INSERT INTO values (name, value) VALUES ('b', 'inserted')")
+	require.NoError(t, err)
+
+	c, err := postgres.Count(ctx, dbPool, "SELECT COUNT(*) FROM audit_log")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), c)
+}
@@ -0,0 +1,40 @@
+package cdc
+
+import (
+	"time"
+
+	"github.com/jackc/pglogrepl"
+)
+
+type Operation string
+
+const (
+	OperationInsert Operation = "INSERT"
+	OperationUpdate Operation = "UPDATE"
+	OperationDelete Operation = "DELETE"
+)
+
+// Change is a row that was inserted, updated or deleted. Values are decoded
+// to the Go types that pgx uses for the column types, such as int64 for
+// bigint, and columns that were not sent are omitted, such as unchanged
+// TOASTed values.
+type Change struct {
+	Operation Operation
+	Schema    string
+	Table     string
+
+	// Xid, CommitTime and LSN identify the transaction that made the change.
+	// LSN is the end of the transaction, which is acknowledged once every
+	// change in the transaction has been handled.
+	Xid        uint32
+	CommitTime time.Time
+	LSN        pglogrepl.LSN
+
+	// Old holds the replica identity of an updated or deleted row, which is
+	// its primary key unless the table has REPLICA IDENTITY FULL. It is nil
+	// for an update that did not change the replica identity.
+	Old map[string]any
+
+	// New holds the inserted or updated row and is nil for a delete.
+	New map[string]any
+}
@@ -0,0 +1,241 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgproto3"
+)
+
+var ErrCDC = errors.New("postgres cdc")
+var ErrDecode = fmt.Errorf("%w: failed to decode", ErrCDC)
+
+// OutputPlugin is the logical decoding plugin that the slot is created with.
+const OutputPlugin = "pgoutput"
+
+// Handler handles a change. Returning an error stops the consumer before the
+// change's transaction is acknowledged, so the transaction is delivered again
+// when the consumer is restarted.
+type Handler func(ctx context.Context, change Change) error
+
+// Consumer streams the changes to the tables of a publication through a
+// logical replication slot. The server must be configured with
+// `wal_level=logical` and the user needs the REPLICATION attribute.
+type Consumer struct {
+	connString     string
+	slot           string
+	publication    string
+	tables         []string
+	statusInterval time.Duration
+}
+
+// NewConsumer creates a consumer for the tables, which may be qualified with
+// a schema such as "public.values", or for all tables when none are given.
+// The slot keeps the position of the consumer across
+// restarts and the server retains WAL until it is acknowledged, so a slot
+// that is no longer consumed should be dropped.
+func NewConsumer(connString string, slot string, publication string, tables ...string) *Consumer {
+	return &Consumer{
+		connString:     connString,
+		slot:           slot,
+		publication:    publication,
+		tables:         tables,
+		statusInterval: 10 * time.Second,
+	}
+}
+
+// Setup creates the publication and the slot unless they already exist.
+func (c *Consumer) Setup(ctx context.Context) error {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	// Create the publication.
+	sql := "CREATE PUBLICATION " + pgx.Identifier{c.publication}.Sanitize()
+	if len(c.tables) == 0 {
+		sql += " FOR ALL TABLES"
+	} else {
+		sql += " FOR TABLE "
+		for i, table := range c.tables {
+			if i > 0 {
+				sql += ", "
+			}
+			sql += pgx.Identifier(strings.Split(table, ".")).Sanitize()
+		}
+	}
+
+	if _, err := conn.Exec(ctx, sql).ReadAll(); err != nil && !isDuplicate(err) {
+		return err
+	}
+
+	// Create the slot.
+	if _, err := pglogrepl.CreateReplicationSlot(ctx, conn, pgx.Identifier{c.slot}.Sanitize(), OutputPlugin,
+		pglogrepl.CreateReplicationSlotOptions{Mode: pglogrepl.LogicalReplication},
+	); err != nil && !isDuplicate(err) {
+		return err
+	}
+
+	// Success.
+	return nil
+}
+
+// Drop drops the slot and the publication.
+func (c *Consumer) Drop(ctx context.Context) error {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if err := pglogrepl.DropReplicationSlot(ctx, conn, pgx.Identifier{c.slot}.Sanitize(),
+		pglogrepl.DropReplicationSlotOptions{Wait: true},
+	); err != nil && !isUndefined(err) {
+		return err
+	}
+
+	sql := "DROP PUBLICATION IF EXISTS " + pgx.Identifier{c.publication}.Sanitize()
+	if _, err := conn.Exec(ctx, sql).ReadAll(); err != nil {
+		return err
+	}
+
+	// Success.
+	return nil
+}
+
+// Run streams changes to the handler until the context is cancelled,
+// resuming after the last acknowledged transaction. Each transaction is
+// acknowledged once the handler has succeeded for all of its changes, so
+// changes are delivered at least once.
+func (c *Consumer) Run(ctx context.Context, handler Handler) error {
+	conn, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	// Start streaming from the slot's confirmed position.
+	if err := pglogrepl.StartReplication(ctx, conn, pgx.Identifier{c.slot}.Sanitize(), 0,
+		pglogrepl.StartReplicationOptions{
+			Mode: pglogrepl.LogicalReplication,
+			PluginArgs: []string{
+				"proto_version '1'",
+				"publication_names '" + strings.ReplaceAll(c.publication, "'", "''") + "'",
+			},
+		},
+	); err != nil {
+		return err
+	}
+
+	d := newDecoder()
+	var acked pglogrepl.LSN
+	statusAt := time.Now().Add(c.statusInterval)
+	for {
+		// Report the acknowledged position regularly so that the server
+		// does not time out the connection.
+		if !time.Now().Before(statusAt) {
+			if err := pglogrepl.SendStandbyStatusUpdate(ctx, conn,
+				pglogrepl.StandbyStatusUpdate{WALWritePosition: acked},
+			); err != nil {
+				return err
+			}
+
+			statusAt = time.Now().Add(c.statusInterval)
+		}
+
+		// Receive the next message.
+		receiveCtx, cancel := context.WithDeadline(ctx, statusAt)
+		rawMsg, err := conn.ReceiveMessage(receiveCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if pgconn.Timeout(err) {
+				continue
+			}
+
+			return err
+		}
+
+		switch msg := rawMsg.(type) {
+		case *pgproto3.ErrorResponse:
+			return pgconn.ErrorResponseToPgError(msg)
+		case *pgproto3.CopyData:
+			if len(msg.Data) == 0 {
+				continue
+			}
+
+			switch msg.Data[0] {
+			case pglogrepl.PrimaryKeepaliveMessageByteID:
+				keepalive, err := pglogrepl.ParsePrimaryKeepaliveMessage(msg.Data[1:])
+				if err != nil {
+					return fmt.Errorf("%w: %w", ErrDecode, err)
+				}
+
+				// Every transaction before the end of the server's WAL has
+				// been handled when none is buffered, so the position can
+				// advance past WAL that has no changes to the tables.
+				if !d.inTransaction() && keepalive.ServerWALEnd > acked {
+					acked = keepalive.ServerWALEnd
+				}
+
+				if keepalive.ReplyRequested {
+					statusAt = time.Time{}
+				}
+			case pglogrepl.XLogDataByteID:
+				xld, err := pglogrepl.ParseXLogData(msg.Data[1:])
+				if err != nil {
+					return fmt.Errorf("%w: %w", ErrDecode, err)
+				}
+
+				changes, lsn, err := d.decode(xld.WALData)
+				if err != nil {
+					return err
+				}
+
+				if lsn == 0 {
+					continue
+				}
+
+				// Handle the transaction, then acknowledge it.
+				for _, change := range changes {
+					if err := handler(ctx, change); err != nil {
+						return err
+					}
+				}
+
+				acked = lsn
+				statusAt = time.Time{}
+			}
+		}
+	}
+}
+
+func (c *Consumer) connect(ctx context.Context) (*pgconn.PgConn, error) {
+	config, err := pgconn.ParseConfig(c.connString)
+	if err != nil {
+		return nil, err
+	}
+
+	config.RuntimeParams["replication"] = "database"
+	return pgconn.ConnectConfig(ctx, config)
+}
+
+func isDuplicate(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "42710"
+}
+
+func isUndefined(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "42704"
+}
@@ -0,0 +1,143 @@
+package cdc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common/internal/pgtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func databasePoolForTesting(t *testing.T) *pgxpool.Pool {
+	return pgtest.Pool(t, "CREATE TABLE values (id BIGSERIAL PRIMARY KEY, name TEXT NOT NULL, value TEXT NOT NULL);")
+}
+
+// slotForTesting returns a unique slot name because slots are shared by all
+// databases on the server.
+func slotForTesting() string {
+	return "test_" + strings.ReplaceAll(uuid.NewString(), "-", "_")
+}
+
+// receive runs the consumer until it has received n changes.
+func receive(t *testing.T, c *Consumer, n int) []Change {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var changes []Change
+	err := c.Run(ctx, func(ctx context.Context, change Change) error {
+		changes = append(changes, change)
+		if len(changes) == n {
+			cancel()
+		}
+
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	return changes
+}
+
+func TestConsumer(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	c := NewConsumer(dbPool.Config().ConnConfig.ConnString(), slotForTesting(), "test_publication", "values")
+	c.statusInterval = 100 * time.Millisecond
+	require.NoError(t, c.Setup(ctx))
+	defer func() { assert.NoError(t, c.Drop(ctx)) }()
+
+	// Setup is idempotent.
+	require.NoError(t, c.Setup(ctx))
+
+	_, err := dbPool.Exec(ctx, "INSERT INTO values (name, value) VALUES ('a', 'inserted')")
+	require.NoError(t, err)
+	_, err = dbPool.Exec(ctx, "UPDATE values SET value = 'updated' WHERE name = 'a'")
+	require.NoError(t, err)
+
+	changes := receive(t, c, 2)
+	require.Len(t, changes, 2)
+	assert.Equal(t, OperationInsert, changes[0].Operation)
+	assert.Equal(t, "values", changes[0].Table)
+	assert.Equal(t, map[string]any{"id": int64(1), "name": "a", "value": "inserted"}, changes[0].New)
+	assert.Equal(t, OperationUpdate, changes[1].Operation)
+	assert.Equal(t, "updated", changes[1].New["value"])
+
+	// Acknowledged changes are not delivered again after a restart.
+	_, err = dbPool.Exec(ctx, "DELETE FROM values")
+	require.NoError(t, err)
+
+	changes = receive(t, c, 1)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OperationDelete, changes[0].Operation)
+	assert.Equal(t, map[string]any{"id": int64(1)}, changes[0].Old)
+}
+
+func TestConsumerSchemaQualifiedTable(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	c := NewConsumer(dbPool.Config().ConnConfig.ConnString(), slotForTesting(), "test_publication", "public.values")
+	require.NoError(t, c.Setup(ctx))
+	defer func() { assert.NoError(t, c.Drop(ctx)) }()
+
+	_, err := dbPool.Exec(ctx, "INSERT INTO values (name, value) VALUES ('a', 'inserted')")
+	require.NoError(t, err)
+
+	changes := receive(t, c, 1)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "public", changes[0].Schema)
+	assert.Equal(t, "values", changes[0].Table)
+}
+
+func TestConsumerHandlerError(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	c := NewConsumer(dbPool.Config().ConnConfig.ConnString(), slotForTesting(), "test_publication")
+	require.NoError(t, c.Setup(ctx))
+	defer func() { assert.NoError(t, c.Drop(ctx)) }()
+
+	_, err := dbPool.Exec(ctx, "INSERT INTO values (name, value) VALUES ('a', 'inserted')")
+	require.NoError(t, err)
+
+	// A failed change is not acknowledged.
+	errFailed := errors.New("failed")
+	err = c.Run(ctx, func(ctx context.Context, change Change) error {
+		return errFailed
+	})
+	assert.ErrorIs(t, err, errFailed)
+
+	changes := receive(t, c, 1)
+	require.Len(t, changes, 1)
+	assert.Equal(t, OperationInsert, changes[0].Operation)
+}
+
+func TestConsumerMissingSlot(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	c := NewConsumer(dbPool.Config().ConnConfig.ConnString(), "missing_slot", "missing_publication")
+	err := c.Run(context.Background(), func(ctx context.Context, change Change) error {
+		return nil
+	})
+
+	var pgErr *pgconn.PgError
+	assert.ErrorAs(t, err, &pgErr)
+}
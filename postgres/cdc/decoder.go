@@ -0,0 +1,153 @@
+package cdc
+
+import (
+	"fmt"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// decoder decodes pgoutput messages, collecting the changes of each
+// transaction until it is committed.
+type decoder struct {
+	typeMap   *pgtype.Map
+	relations map[uint32]*pglogrepl.RelationMessage
+	begin     *pglogrepl.BeginMessage
+	changes   []Change
+}
+
+func newDecoder() *decoder {
+	return &decoder{
+		typeMap:   pgtype.NewMap(),
+		relations: make(map[uint32]*pglogrepl.RelationMessage),
+	}
+}
+
+// decode decodes a message. When the message commits a transaction, it
+// returns the changes of the transaction and the LSN to acknowledge, which is
+// set even when the transaction has no changes to the published tables.
+func (d *decoder) decode(data []byte) ([]Change, pglogrepl.LSN, error) {
+	msg, err := pglogrepl.Parse(data)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", ErrDecode, err)
+	}
+
+	switch msg := msg.(type) {
+	case *pglogrepl.RelationMessage:
+		d.relations[msg.RelationID] = msg
+	case *pglogrepl.BeginMessage:
+		d.begin = msg
+		d.changes = nil
+	case *pglogrepl.InsertMessage:
+		return nil, 0, d.add(OperationInsert, msg.RelationID, 0, nil, msg.Tuple)
+	case *pglogrepl.UpdateMessage:
+		return nil, 0, d.add(OperationUpdate, msg.RelationID, msg.OldTupleType, msg.OldTuple, msg.NewTuple)
+	case *pglogrepl.DeleteMessage:
+		return nil, 0, d.add(OperationDelete, msg.RelationID, msg.OldTupleType, msg.OldTuple, nil)
+	case *pglogrepl.CommitMessage:
+		if d.begin == nil {
+			return nil, 0, fmt.Errorf("%w: commit without begin", ErrDecode)
+		}
+
+		changes := d.changes
+		for i := range changes {
+			changes[i].LSN = msg.TransactionEndLSN
+		}
+
+		d.begin = nil
+		d.changes = nil
+		return changes, msg.TransactionEndLSN, nil
+	}
+
+	// Success.
+	return nil, 0, nil
+}
+
+// inTransaction reports whether a transaction has begun and not committed.
+func (d *decoder) inTransaction() bool {
+	return d.begin != nil
+}
+
+func (d *decoder) add(
+	op Operation,
+	relationID uint32,
+	oldTupleType uint8,
+	oldTuple *pglogrepl.TupleData,
+	newTuple *pglogrepl.TupleData,
+) error {
+	if d.begin == nil {
+		return fmt.Errorf("%w: %s without begin", ErrDecode, op)
+	}
+
+	relation, ok := d.relations[relationID]
+	if !ok {
+		return fmt.Errorf("%w: unknown relation %d", ErrDecode, relationID)
+	}
+
+	oldValues, err := d.values(relation, oldTuple, oldTupleType == pglogrepl.UpdateMessageTupleTypeKey)
+	if err != nil {
+		return err
+	}
+
+	newValues, err := d.values(relation, newTuple, false)
+	if err != nil {
+		return err
+	}
+
+	d.changes = append(d.changes, Change{
+		Operation:  op,
+		Schema:     relation.Namespace,
+		Table:      relation.RelationName,
+		Xid:        d.begin.Xid,
+		CommitTime: d.begin.CommitTime,
+		Old:        oldValues,
+		New:        newValues,
+	})
+
+	// Success.
+	return nil
+}
+
+// values decodes the columns of the tuple. A key tuple only holds the
+// replica identity, so the other columns are omitted rather than NULL.
+func (d *decoder) values(relation *pglogrepl.RelationMessage, tuple *pglogrepl.TupleData, key bool) (map[string]any, error) {
+	if tuple == nil {
+		return nil, nil
+	}
+
+	values := make(map[string]any, len(tuple.Columns))
+	for i, col := range tuple.Columns {
+		if i >= len(relation.Columns) {
+			return nil, fmt.Errorf("%w: %s has more columns than expected", ErrDecode, relation.RelationName)
+		}
+
+		if key && relation.Columns[i].Flags != 1 {
+			continue
+		}
+
+		name := relation.Columns[i].Name
+		switch col.DataType {
+		case pglogrepl.TupleDataTypeNull:
+			values[name] = nil
+		case pglogrepl.TupleDataTypeText:
+			value, err := d.value(relation.Columns[i].DataType, col.Data)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s.%s: %w", ErrDecode, relation.RelationName, name, err)
+			}
+			values[name] = value
+		}
+	}
+
+	// Success.
+	return values, nil
+}
+
+// value decodes a column in text format, falling back to the text for
+// unregistered types.
+func (d *decoder) value(oid uint32, data []byte) (any, error) {
+	if t, ok := d.typeMap.TypeForOID(oid); ok {
+		return t.Codec.DecodeValue(d.typeMap, oid, pgtype.TextFormatCode, data)
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,161 @@
+package cdc
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/jackc/pglogrepl"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type message []byte
+
+func (m message) byte(b byte) message {
+	return append(m, b)
+}
+
+func (m message) uint16(v uint16) message {
+	return binary.BigEndian.AppendUint16(m, v)
+}
+
+func (m message) uint32(v uint32) message {
+	return binary.BigEndian.AppendUint32(m, v)
+}
+
+func (m message) uint64(v uint64) message {
+	return binary.BigEndian.AppendUint64(m, v)
+}
+
+func (m message) string(s string) message {
+	return append(append(m, s...), 0)
+}
+
+// tuple encodes the values in text format, with nil as NULL.
+func (m message) tuple(values ...*string) message {
+	m = m.uint16(uint16(len(values)))
+	for _, v := range values {
+		if v == nil {
+			m = m.byte('n')
+			continue
+		}
+
+		m = m.byte('t').uint32(uint32(len(*v)))
+		m = append(m, *v...)
+	}
+
+	return m
+}
+
+func text(s string) *string {
+	return &s
+}
+
+func relationMessage() message {
+	return message{'R'}.
+		uint32(1).
+		string("public").
+		string("values").
+		byte('d').
+		uint16(3).
+		byte(1).string("id").uint32(pgtype.Int8OID).uint32(0xFFFFFFFF).
+		byte(0).string("name").uint32(pgtype.TextOID).uint32(0xFFFFFFFF).
+		byte(0).string("data").uint32(99999).uint32(0xFFFFFFFF)
+}
+
+func beginMessage(xid uint32) message {
+	return message{'B'}.uint64(100).uint64(0).uint32(xid)
+}
+
+func commitMessage(lsn uint64) message {
+	return message{'C'}.byte(0).uint64(lsn - 1).uint64(lsn).uint64(0)
+}
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+
+	d := newDecoder()
+	messages := []message{
+		relationMessage(),
+		beginMessage(7),
+		message{'I'}.uint32(1).byte('N').tuple(text("1"), text("a"), nil),
+		message{'U'}.uint32(1).byte('K').tuple(text("1"), nil, nil).byte('N').tuple(text("2"), text("b"), text("raw")),
+		message{'D'}.uint32(1).byte('K').tuple(text("2"), nil, nil),
+	}
+	for _, msg := range messages {
+		changes, lsn, err := d.decode(msg)
+		require.NoError(t, err)
+		assert.Empty(t, changes)
+		assert.Zero(t, lsn)
+	}
+
+	changes, lsn, err := d.decode(commitMessage(200))
+	require.NoError(t, err)
+	assert.Equal(t, pglogrepl.LSN(200), lsn)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, OperationInsert, changes[0].Operation)
+	assert.Equal(t, "public", changes[0].Schema)
+	assert.Equal(t, "values", changes[0].Table)
+	assert.Equal(t, uint32(7), changes[0].Xid)
+	assert.Equal(t, pglogrepl.LSN(200), changes[0].LSN)
+	assert.Nil(t, changes[0].Old)
+	assert.Equal(t, map[string]any{"id": int64(1), "name": "a", "data": nil}, changes[0].New)
+
+	assert.Equal(t, OperationUpdate, changes[1].Operation)
+	assert.Equal(t, map[string]any{"id": int64(1)}, changes[1].Old)
+	assert.Equal(t, map[string]any{"id": int64(2), "name": "b", "data": "raw"}, changes[1].New)
+
+	assert.Equal(t, OperationDelete, changes[2].Operation)
+	assert.Equal(t, map[string]any{"id": int64(2)}, changes[2].Old)
+	assert.Nil(t, changes[2].New)
+}
+
+func TestDecoderEmptyTransaction(t *testing.T) {
+	t.Parallel()
+
+	d := newDecoder()
+	assert.False(t, d.inTransaction())
+
+	_, _, err := d.decode(beginMessage(1))
+	require.NoError(t, err)
+	assert.True(t, d.inTransaction())
+
+	changes, lsn, err := d.decode(commitMessage(300))
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	assert.Equal(t, pglogrepl.LSN(300), lsn)
+	assert.False(t, d.inTransaction())
+}
+
+func TestDecoderErrors(t *testing.T) {
+	t.Parallel()
+
+	// A change before its transaction begins.
+	d := newDecoder()
+	_, _, err := d.decode(relationMessage())
+	require.NoError(t, err)
+	_, _, err = d.decode(message{'I'}.uint32(1).byte('N').tuple(text("1"), text("a"), nil))
+	assert.ErrorIs(t, err, ErrDecode)
+
+	// A change to an unknown relation.
+	d = newDecoder()
+	_, _, err = d.decode(beginMessage(1))
+	require.NoError(t, err)
+	_, _, err = d.decode(message{'I'}.uint32(2).byte('N').tuple(text("1")))
+	assert.ErrorIs(t, err, ErrDecode)
+
+	// A value that cannot be decoded.
+	d = newDecoder()
+	_, _, err = d.decode(relationMessage())
+	require.NoError(t, err)
+	_, _, err = d.decode(beginMessage(1))
+	require.NoError(t, err)
+	_, _, err = d.decode(message{'I'}.uint32(1).byte('N').tuple(text("x"), text("a"), nil))
+	assert.ErrorIs(t, err, ErrDecode)
+
+	// An unsupported message.
+	_, _, err = newDecoder().decode(message{'Z'})
+	assert.ErrorIs(t, err, ErrDecode)
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Collector gathers conversion errors while mapping a row so that every NULL
+// violation is reported together rather than only the first.
+//
+//	var c postgres.Collector
+//	user := User{
+//		Name:  postgres.Collect(&c, "name", postgres.TryText[string], r.Name),
+//		Email: postgres.Collect(&c, "email", postgres.TryText[string], r.Email),
+//	}
+//	if err := c.Err(); err != nil {
+//		return nil, err
+//	}
+type Collector struct {
+	errs []error
+}
+
+// Collect converts v with fn, recording any error against column and
+// returning the zero value in its place.
+func Collect[T any, U any](c *Collector, column string, fn func(U) (T, error), v U) T {
+	val, err := fn(v)
+	if err != nil {
+		c.errs = append(c.errs, fmt.Errorf("%s: %w", column, err))
+	}
+
+	return val
+}
+
+// Err returns the joined errors, or nil when every conversion succeeded.
+func (c *Collector) Err() error {
+	return errors.Join(c.errs...)
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	var c Collector
+	name := Collect(&c, "name", TryText[string], pgtype.Text{String: "Alice", Valid: true})
+	age := Collect(&c, "age", TryInt4[int], pgtype.Int4{Int32: 30, Valid: true})
+
+	assert.Equal(t, "Alice", name)
+	assert.Equal(t, 30, age)
+	assert.NoError(t, c.Err())
+}
+
+func TestCollectMultipleErrors(t *testing.T) {
+	t.Parallel()
+
+	var c Collector
+	name := Collect(&c, "name", TryText[string], pgtype.Text{})
+	age := Collect(&c, "age", TryInt4[int], pgtype.Int4{Int32: 30, Valid: true})
+	email := Collect(&c, "email", TryText[string], pgtype.Text{})
+
+	assert.Empty(t, name)
+	assert.Equal(t, 30, age)
+	assert.Empty(t, email)
+
+	err := c.Err()
+	assert.ErrorIs(t, err, ErrUnexpectedNull)
+	assert.EqualError(t, err, "name: unexpected null: text\nemail: unexpected null: text")
+}
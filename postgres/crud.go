@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jeremybower/go-common"
 	"github.com/jeremybower/go-common/pagination"
 )
 
@@ -14,11 +15,20 @@ func CountT(
 	data map[string]any,
 ) (int64, error) {
 	// Execute the template to build the SQL.
-	sql, args, err := templ.ExecuteCount(data)
+	ctx = withTemplateName(ctx, templ)
+	sql, args, err := templ.ExecuteCountContext(ctx, data)
 	if err != nil {
 		return 0, NormalizeError(err)
 	}
 
+	// Use the prepared statement when available.
+	querier, sql, release, err := prepared(ctx, querier, templ, sql)
+	if err != nil {
+		return 0, NormalizeError(err)
+	}
+	defer release()
+
+	// Success.
 	return Count(ctx, querier, sql, args...)
 }
 
@@ -45,15 +55,56 @@ func ExecT(
 	data map[string]any,
 ) (int64, error) {
 	// Execute the template to build the SQL.
-	sql, args, err := templ.Execute(data)
+	ctx = withTemplateName(ctx, templ)
+	sql, args, err := templ.ExecuteContext(ctx, data)
+	if err != nil {
+		return 0, NormalizeError(err)
+	}
+
+	// Use the prepared statement when available.
+	querier, sql, release, err := prepared(ctx, querier, templ, sql)
 	if err != nil {
 		return 0, NormalizeError(err)
 	}
+	defer release()
 
 	// Success.
 	return Exec(ctx, querier, sql, args...)
 }
 
+// ExecVersionT runs a template that changes a row only when its version is
+// unchanged, such as `UPDATE values SET value = {{ arg .Value }}, version =
+// version + 1 WHERE id = {{ arg .ID }} AND version = {{ arg .Version }}`.
+// When no rows are affected, it counts the rows with existsTempl, such as
+// `SELECT COUNT(*) FROM values WHERE id = {{ arg .ID }}`, and fails with
+// common.ErrStaleVersion when the row exists and common.ErrNotFound when it
+// does not. Both templates are executed with the same data.
+func ExecVersionT(
+	ctx context.Context,
+	querier Querier,
+	templ *Template,
+	existsTempl *Template,
+	data map[string]any,
+) (int64, error) {
+	// Change the row.
+	c, err := ExecT(ctx, querier, templ, data)
+	if err != nil || c > 0 {
+		return c, err
+	}
+
+	// Distinguish a changed row from a missing row.
+	c, err = CountT(ctx, querier, existsTempl, data)
+	if err != nil {
+		return 0, err
+	}
+
+	if c > 0 {
+		return 0, common.ErrStaleVersion
+	}
+
+	return 0, common.ErrNotFound
+}
+
 func Exec(
 	ctx context.Context,
 	querier Querier,
@@ -77,10 +128,18 @@ func ReadOneT[T any](
 	data map[string]any,
 ) (*T, error) {
 	// Execute the template to build the SQL.
-	sql, args, err := templ.Execute(data)
+	ctx = withTemplateName(ctx, templ)
+	sql, args, err := templ.ExecuteContext(ctx, data)
+	if err != nil {
+		return nil, NormalizeError(err)
+	}
+
+	// Use the prepared statement when available.
+	querier, sql, release, err := prepared(ctx, querier, templ, sql)
 	if err != nil {
 		return nil, NormalizeError(err)
 	}
+	defer release()
 
 	// Success.
 	return ReadOne[T](ctx, querier, sql, args...)
@@ -109,11 +168,19 @@ func ReadManyT[T any](
 	data map[string]any,
 ) ([]*T, error) {
 	// Execute the template to build the SQL.
-	sql, args, err := templ.Execute(data)
+	ctx = withTemplateName(ctx, templ)
+	sql, args, err := templ.ExecuteContext(ctx, data)
 	if err != nil {
 		return nil, NormalizeError(err)
 	}
 
+	// Use the prepared statement when available.
+	querier, sql, release, err := prepared(ctx, querier, templ, sql)
+	if err != nil {
+		return nil, NormalizeError(err)
+	}
+	defer release()
+
 	// Success.
 	return ReadMany[T](ctx, querier, sql, args...)
 }
@@ -155,10 +222,18 @@ func ListT[T any](
 	norm := pagination.Normalize(totalItems, pageIndex, pageSize, minimumPageSize, defaultPageSize, maximumPageSize)
 
 	// Execute the template to build the SQL.
-	sql, args, err := templ.ExecuteList(data, norm.FirstItemIndex, norm.PageSize)
+	ctx = withTemplateName(ctx, templ)
+	sql, args, err := templ.ExecuteListContext(ctx, data, norm.FirstItemIndex, norm.PageSize)
+	if err != nil {
+		return nil, NormalizeError(err)
+	}
+
+	// Use the prepared statement when available.
+	querier, sql, release, err := prepared(ctx, querier, templ, sql)
 	if err != nil {
 		return nil, NormalizeError(err)
 	}
+	defer release()
 
 	// Execute the SQL to list the items.
 	var items []*T
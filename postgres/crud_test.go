@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"testing"
 
+	"github.com/jeremybower/go-common"
 	"github.com/jeremybower/go-common/pagination"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -56,6 +57,37 @@ func TestExecT(t *testing.T) {
 	assert.Equal(t, int64(0), c)
 }
 
+func TestExecVersionT(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := dbPool.Exec(ctx, "CREATE TABLE versioned (id BIGSERIAL PRIMARY KEY, value TEXT NOT NULL, version BIGINT NOT NULL DEFAULT 1)")
+	require.NoError(t, err)
+
+	var id int64
+	err = dbPool.QueryRow(ctx, "INSERT INTO versioned (value) VALUES ('a') RETURNING id").Scan(&id)
+	require.NoError(t, err)
+
+	templ := MustParse("UPDATE versioned SET value = {{ arg .Value }}, version = version + 1 WHERE id = {{ arg .ID }} AND version = {{ arg .Version }}")
+	existsTempl := MustParse("SELECT COUNT(*) FROM versioned WHERE id = {{ arg .ID }}")
+
+	// The expected version is updated.
+	c, err := ExecVersionT(ctx, dbPool, templ, existsTempl, map[string]any{"ID": id, "Value": "b", "Version": 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	// A lost update is stale.
+	_, err = ExecVersionT(ctx, dbPool, templ, existsTempl, map[string]any{"ID": id, "Value": "c", "Version": 1})
+	assert.ErrorIs(t, err, common.ErrStaleVersion)
+
+	// A missing row is not found.
+	_, err = ExecVersionT(ctx, dbPool, templ, existsTempl, map[string]any{"ID": id + 1, "Value": "c", "Version": 2})
+	assert.ErrorIs(t, err, common.ErrNotFound)
+}
+
 func TestReadOneT(t *testing.T) {
 	t.Parallel()
 
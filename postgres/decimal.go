@@ -0,0 +1,372 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var ErrInvalidDecimal = errors.New("invalid decimal")
+var ErrDivisionByZero = errors.New("division by zero")
+var ErrDecimalOverflow = errors.New("decimal overflow")
+
+// MaxDecimalDigits and MaxDecimalScale are the limits of a Postgres numeric:
+// up to 131072 digits before the decimal point and 16383 after it. Decimals
+// outside them are rejected, which also bounds the work of formatting and
+// aligning their exponents.
+const (
+	MaxDecimalDigits = 131072
+	MaxDecimalScale  = 16383
+)
+
+// Decimal is an exact decimal number equal to coef * 10^exp. The zero value
+// is zero. Like Postgres, the exponent is preserved so 1.50 and 1.5 are equal
+// but are formatted differently.
+type Decimal struct {
+	coef *big.Int
+	exp  int32
+}
+
+// NewDecimal fails with ErrDecimalOverflow when the decimal is outside the
+// limits of a Postgres numeric.
+func NewDecimal(coef int64, exp int32) (Decimal, error) {
+	return NewDecimalFromBigInt(big.NewInt(coef), exp)
+}
+
+func MustNewDecimal(coef int64, exp int32) Decimal {
+	d, err := NewDecimal(coef, exp)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func NewDecimalFromBigInt(coef *big.Int, exp int32) (Decimal, error) {
+	if err := checkDecimal(coef, int64(exp)); err != nil {
+		return Decimal{}, err
+	}
+
+	return Decimal{coef: new(big.Int).Set(coef), exp: exp}, nil
+}
+
+// checkDecimal fails with ErrDecimalOverflow when coef * 10^exp has more
+// digits before or after the decimal point than a Postgres numeric.
+func checkDecimal(coef *big.Int, exp int64) error {
+	if exp < -MaxDecimalScale {
+		return fmt.Errorf("%w: %d digits after the decimal point", ErrDecimalOverflow, -exp)
+	}
+
+	// Each digit has more than 3 bits, so this avoids formatting huge
+	// coefficients.
+	if coef.BitLen() > 4*(MaxDecimalDigits+MaxDecimalScale) {
+		return fmt.Errorf("%w: too many digits", ErrDecimalOverflow)
+	}
+
+	digits := int64(1)
+	if coef.Sign() != 0 {
+		digits = int64(len(new(big.Int).Abs(coef).String()))
+	}
+
+	if digits+exp > MaxDecimalDigits {
+		return fmt.Errorf("%w: %d digits before the decimal point", ErrDecimalOverflow, digits+exp)
+	}
+
+	return nil
+}
+
+// ParseDecimal parses a decimal such as "-1.50" or "1e3", failing with
+// ErrInvalidDecimal when it is malformed or outside the limits of a Postgres
+// numeric.
+
+func ParseDecimal(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+
+	// Split the exponent.
+	var exp int64
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		var err error
+		exp, err = strconv.ParseInt(str[i+1:], 10, 32)
+		if err != nil {
+			return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+		}
+		str = str[:i]
+	}
+
+	// Remove the decimal point, adjusting the exponent.
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		exp -= int64(len(str) - i - 1)
+		str = str[:i] + str[i+1:]
+	}
+
+	digits := strings.TrimLeft(str, "+-")
+	if digits == "" || len(str)-len(digits) > 1 || strings.ContainsAny(digits, "+-") {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+	}
+
+	coef, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrInvalidDecimal, s)
+	}
+
+	if err := checkDecimal(coef, exp); err != nil {
+		return Decimal{}, fmt.Errorf("%w: %s: %w", ErrInvalidDecimal, s, err)
+	}
+
+	return Decimal{coef: coef, exp: int32(exp)}, nil
+}
+
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func (d Decimal) Coefficient() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Set(d.coef)
+}
+
+func (d Decimal) Exponent() int32 {
+	return d.exp
+}
+
+func (d Decimal) Sign() int {
+	if d.coef == nil {
+		return 0
+	}
+
+	return d.coef.Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	a, b, _ := align(d, other)
+	return a.Cmp(b)
+}
+
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.Coefficient()), exp: d.exp}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.Coefficient()), exp: d.exp}
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	a, b, exp := align(d, other)
+	return Decimal{coef: a.Add(a, b), exp: exp}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	a, b, exp := align(d, other)
+	return Decimal{coef: a.Sub(a, b), exp: exp}
+}
+
+// Mul multiplies by other, failing with ErrDecimalOverflow when the product
+// is outside the limits of a Postgres numeric.
+func (d Decimal) Mul(other Decimal) (Decimal, error) {
+	exp := int64(d.exp) + int64(other.exp)
+	coef := new(big.Int).Mul(d.Coefficient(), other.Coefficient())
+	if err := checkDecimal(coef, exp); err != nil {
+		return Decimal{}, err
+	}
+
+	return Decimal{coef: coef, exp: int32(exp)}, nil
+}
+
+// Quo divides by other and rounds the result to scale digits after the
+// decimal point, rounding half away from zero like Postgres. It fails with
+// ErrDecimalOverflow when the quotient is outside the limits of a Postgres
+// numeric.
+func (d Decimal) Quo(other Decimal, scale int32) (Decimal, error) {
+	if other.IsZero() {
+		return Decimal{}, ErrDivisionByZero
+	}
+
+	if scale < -MaxDecimalDigits || scale > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("%w: scale %d", ErrDecimalOverflow, scale)
+	}
+
+	r := new(big.Rat).Quo(d.Rat(), other.Rat())
+	coef := roundRat(r, scale)
+	if err := checkDecimal(coef, -int64(scale)); err != nil {
+		return Decimal{}, err
+	}
+
+	return Decimal{coef: coef, exp: -scale}, nil
+}
+
+// Round rounds to scale digits after the decimal point, rounding half away
+// from zero like Postgres. The scale is limited to MaxDecimalScale digits
+// after the decimal point and MaxDecimalDigits before it.
+func (d Decimal) Round(scale int32) Decimal {
+	scale = max(-MaxDecimalDigits, min(scale, MaxDecimalScale))
+	return Decimal{coef: roundRat(d.Rat(), scale), exp: -scale}
+}
+
+func (d Decimal) Rat() *big.Rat {
+	r := new(big.Rat).SetInt(d.Coefficient())
+	return r.Mul(r, pow10Rat(int64(d.exp)))
+}
+
+// BigInt returns the decimal as an integer or an error if it has a
+// fractional part.
+func (d Decimal) BigInt() (*big.Int, error) {
+	r := d.Rat()
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%w: %s", ErrNumericNotInteger, d)
+	}
+
+	return new(big.Int).Set(r.Num()), nil
+}
+
+func (d Decimal) String() string {
+	coef := d.Coefficient()
+	if d.exp >= 0 {
+		if coef.Sign() == 0 {
+			return "0"
+		}
+
+		return coef.String() + strings.Repeat("0", int(d.exp))
+	}
+
+	sign := ""
+	if coef.Sign() < 0 {
+		sign = "-"
+		coef.Neg(coef)
+	}
+
+	digits := coef.String()
+	scale := int(-d.exp)
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+
+	point := len(digits) - scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a JSON string or number.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	str := string(data)
+	if strings.HasPrefix(str, `"`) {
+		if err := json.Unmarshal(data, &str); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidDecimal, err)
+		}
+	}
+
+	v, err := ParseDecimal(str)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
+
+func (d *Decimal) ScanNumeric(n pgtype.Numeric) error {
+	v, err := ToDecimal(n)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+	return nil
+}
+
+func (d Decimal) NumericValue() (pgtype.Numeric, error) {
+	return pgtype.Numeric{Int: d.Coefficient(), Exp: d.exp, Valid: true}, nil
+}
+
+func (d *Decimal) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		v, err := ParseDecimal(src)
+		if err != nil {
+			return err
+		}
+		*d = v
+		return nil
+	case []byte:
+		return d.Scan(string(src))
+	case int64:
+		*d = MustNewDecimal(src, 0)
+		return nil
+	case nil:
+		return fmt.Errorf("%w: null", ErrNumeric)
+	}
+
+	return fmt.Errorf("cannot scan %T into %T", src, d)
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// align returns copies of the coefficients scaled to the smaller of the two
+// exponents.
+func align(a Decimal, b Decimal) (*big.Int, *big.Int, int32) {
+	ac := a.Coefficient()
+	bc := b.Coefficient()
+	switch {
+	case a.exp > b.exp:
+		ac.Mul(ac, pow10(int64(a.exp)-int64(b.exp)))
+		return ac, bc, b.exp
+	case b.exp > a.exp:
+		bc.Mul(bc, pow10(int64(b.exp)-int64(a.exp)))
+		return ac, bc, a.exp
+	}
+
+	return ac, bc, a.exp
+}
+
+// roundRat returns r * 10^scale rounded half away from zero.
+func roundRat(r *big.Rat, scale int32) *big.Int {
+	scaled := new(big.Rat).Mul(r, pow10Rat(int64(scale)))
+	q, rem := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+	if rem.Sign() != 0 {
+		twice := new(big.Int).Abs(rem)
+		twice.Lsh(twice, 1)
+		if twice.Cmp(scaled.Denom()) >= 0 {
+			q.Add(q, big.NewInt(int64(scaled.Sign())))
+		}
+	}
+
+	return q
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+func pow10Rat(n int64) *big.Rat {
+	if n >= 0 {
+		return new(big.Rat).SetInt(pow10(n))
+	}
+
+	return new(big.Rat).SetFrac(big.NewInt(1), pow10(-n))
+}
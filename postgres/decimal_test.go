@@ -0,0 +1,257 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDecimal(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input       string
+		expectedErr error
+		expected    string
+	}{
+		{"0", nil, "0"},
+		{"123", nil, "123"},
+		{"-123.45", nil, "-123.45"},
+		{"+0.050", nil, "0.050"},
+		{".5", nil, "0.5"},
+		{"-.5", nil, "-0.5"},
+		{"5.", nil, "5"},
+		{"1.5e3", nil, "1500"},
+		{"15E-3", nil, "0.015"},
+		{"", ErrInvalidDecimal, ""},
+		{"-", ErrInvalidDecimal, ""},
+		{"--1", ErrInvalidDecimal, ""},
+		{"1.2.3", ErrInvalidDecimal, ""},
+		{"1e", ErrInvalidDecimal, ""},
+		{"abc", ErrInvalidDecimal, ""},
+		{"1_000", ErrInvalidDecimal, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			d, err := ParseDecimal(tt.input)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, d.String())
+			}
+		})
+	}
+
+	assert.Panics(t, func() { MustParseDecimal("abc") })
+}
+
+func TestDecimalString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "0", Decimal{}.String())
+	assert.Equal(t, "0.00", MustNewDecimal(0, -2).String())
+	assert.Equal(t, "1200", MustNewDecimal(12, 2).String())
+	assert.Equal(t, "-0.012", MustNewDecimal(-12, -3).String())
+	assert.Equal(t, "1.50", MustNewDecimal(150, -2).String())
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	t.Parallel()
+
+	a := MustParseDecimal("10.25")
+	b := MustParseDecimal("-2.5")
+
+	assert.Equal(t, "7.75", a.Add(b).String())
+	assert.Equal(t, "12.75", a.Sub(b).String())
+	product, err := a.Mul(b)
+	require.NoError(t, err)
+	assert.Equal(t, "-25.625", product.String())
+	assert.Equal(t, "2.5", b.Neg().String())
+	assert.Equal(t, "2.5", b.Abs().String())
+	assert.Equal(t, 1, a.Cmp(b))
+	assert.Equal(t, -1, b.Cmp(a))
+	assert.True(t, MustParseDecimal("1.50").Equal(MustParseDecimal("1.5")))
+	assert.Equal(t, -1, b.Sign())
+	assert.True(t, Decimal{}.IsZero())
+
+	q, err := a.Quo(b, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "-4.10", q.String())
+
+	q, err = MustParseDecimal("2").Quo(MustParseDecimal("3"), 4)
+	require.NoError(t, err)
+	assert.Equal(t, "0.6667", q.String())
+
+	_, err = a.Quo(Decimal{}, 2)
+	assert.ErrorIs(t, err, ErrDivisionByZero)
+
+	assert.Equal(t, "1.3", MustParseDecimal("1.25").Round(1).String())
+	assert.Equal(t, "-1.3", MustParseDecimal("-1.25").Round(1).String())
+	assert.Equal(t, "1.2", MustParseDecimal("1.24").Round(1).String())
+	assert.Equal(t, "1.000", MustParseDecimal("1").Round(3).String())
+}
+
+func TestDecimalLimits(t *testing.T) {
+	t.Parallel()
+
+	// Huge exponents are rejected when parsing, including from JSON.
+	for _, str := range []string{"1e900000000", "1e-900000000", "1e2147483647", "1e-2147483648", "1e131072", "1e-16384"} {
+		_, err := ParseDecimal(str)
+		assert.ErrorIs(t, err, ErrInvalidDecimal, str)
+		assert.ErrorIs(t, err, ErrDecimalOverflow, str)
+
+		var d Decimal
+		assert.ErrorIs(t, json.Unmarshal([]byte(`"`+str+`"`), &d), ErrDecimalOverflow, str)
+	}
+
+	// The limits of a Postgres numeric are accepted.
+	d, err := ParseDecimal("1e131071")
+	require.NoError(t, err)
+	assert.Len(t, d.String(), MaxDecimalDigits)
+
+	d, err = ParseDecimal("1e-16383")
+	require.NoError(t, err)
+	assert.Len(t, d.String(), MaxDecimalScale+2)
+
+	// Constructors check the limits too.
+	_, err = NewDecimal(1, math.MaxInt32)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	_, err = NewDecimal(12, MaxDecimalDigits-1)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	_, err = NewDecimal(1, math.MinInt32)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	_, err = NewDecimalFromBigInt(new(big.Int).Lsh(big.NewInt(1), 1<<20), 0)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	_, err = ToDecimal(pgtype.Numeric{Int: big.NewInt(1), Exp: math.MaxInt32, Valid: true})
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	// Differences between the extreme exponents do not overflow.
+	lo := MustNewDecimal(1, -MaxDecimalScale)
+	hi := MustNewDecimal(1, MaxDecimalDigits-1)
+	assert.Equal(t, -1, lo.Cmp(hi))
+	assert.Equal(t, 1, hi.Cmp(lo))
+}
+
+func TestDecimalMulOverflow(t *testing.T) {
+	t.Parallel()
+
+	_, err := MustNewDecimal(1, MaxDecimalDigits-1).Mul(MustNewDecimal(1, 1))
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	_, err = MustNewDecimal(1, -MaxDecimalScale).Mul(MustNewDecimal(1, -1))
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	product, err := MustNewDecimal(2, MaxDecimalDigits-1).Mul(MustNewDecimal(3, 0))
+	require.NoError(t, err)
+	assert.Equal(t, MustNewDecimal(6, MaxDecimalDigits-1), product)
+
+	_, err = MustNewDecimal(1, 0).Quo(MustNewDecimal(3, 0), math.MaxInt32)
+	assert.ErrorIs(t, err, ErrDecimalOverflow)
+
+	assert.Equal(t, int32(-MaxDecimalScale), MustParseDecimal("1").Round(math.MaxInt32).Exponent())
+}
+
+func TestDecimalConversions(t *testing.T) {
+	t.Parallel()
+
+	d := MustParseDecimal("1.25")
+	assert.Equal(t, big.NewRat(5, 4), d.Rat())
+	assert.Equal(t, big.NewInt(125), d.Coefficient())
+	assert.Equal(t, int32(-2), d.Exponent())
+
+	_, err := d.BigInt()
+	assert.ErrorIs(t, err, ErrNumericNotInteger)
+
+	i, err := MustParseDecimal("1.20e2").BigInt()
+	require.NoError(t, err)
+	assert.Equal(t, big.NewInt(120), i)
+}
+
+func TestDecimalJSON(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(MustParseDecimal("1.50"))
+	require.NoError(t, err)
+	assert.Equal(t, `"1.50"`, string(b))
+
+	var d Decimal
+	require.NoError(t, json.Unmarshal([]byte(`"1.50"`), &d))
+	assert.Equal(t, "1.50", d.String())
+
+	require.NoError(t, json.Unmarshal([]byte(`2.25`), &d))
+	assert.Equal(t, "2.25", d.String())
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`"abc"`), &d), ErrInvalidDecimal)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`true`), &d), ErrInvalidDecimal)
+}
+
+func TestDecimalScan(t *testing.T) {
+	t.Parallel()
+
+	var d Decimal
+	require.NoError(t, d.Scan("1.50"))
+	assert.Equal(t, "1.50", d.String())
+	require.NoError(t, d.Scan([]byte("2.5")))
+	assert.Equal(t, "2.5", d.String())
+	require.NoError(t, d.Scan(int64(3)))
+	assert.Equal(t, "3", d.String())
+	assert.ErrorIs(t, d.Scan(nil), ErrNumeric)
+	assert.Error(t, d.Scan(1.5))
+
+	require.NoError(t, d.ScanNumeric(pgtype.Numeric{Int: big.NewInt(15), Exp: -1, Valid: true}))
+	assert.Equal(t, "1.5", d.String())
+	assert.ErrorIs(t, d.ScanNumeric(pgtype.Numeric{NaN: true, Valid: true}), ErrNumericNaN)
+
+	n, err := d.NumericValue()
+	require.NoError(t, err)
+	assert.Equal(t, pgtype.Numeric{Int: big.NewInt(15), Exp: -1, Valid: true}, n)
+
+	v, err := d.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "1.5", v)
+}
+
+func TestDecimalRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := dbPool.Exec(ctx, "CREATE TABLE amounts (id BIGSERIAL PRIMARY KEY, amount NUMERIC(20, 4) NOT NULL, other NUMERIC);")
+	require.NoError(t, err)
+
+	type amountRow struct {
+		ID     int64          `db:"id"`
+		Amount Decimal        `db:"amount"`
+		Other  pgtype.Numeric `db:"other"`
+	}
+
+	_, err = Exec(ctx, dbPool, "INSERT INTO amounts (amount, other) VALUES ($1, 'NaN'), ($2, NULL);", MustParseDecimal("12345678901234.5678"), MustParseDecimal("-0.1"))
+	require.NoError(t, err)
+
+	rows, err := ReadMany[amountRow](ctx, dbPool, "SELECT * FROM amounts ORDER BY id;")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "12345678901234.5678", rows[0].Amount.String())
+	assert.Equal(t, "-0.1000", rows[1].Amount.String())
+
+	_, err = TryNumeric(rows[0].Other, ToDecimal)
+	assert.ErrorIs(t, err, ErrNumericNaN)
+
+	other, err := NilableNumeric(rows[1].Other, ToDecimal)
+	require.NoError(t, err)
+	assert.Nil(t, other.Value)
+}
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeremybower/go-common/nilable"
+)
+
+var ErrEnum = errors.New("postgres enum")
+var ErrInvalidEnumValue = fmt.Errorf("%w: invalid value", ErrEnum)
+var ErrEnumMismatch = fmt.Errorf("%w: values are not defined in the database", ErrEnum)
+
+// Enum describes a Postgres enum type and the Go values that map to its
+// labels.
+type Enum[T ~string] struct {
+	name   string
+	values []T
+}
+
+func NewEnum[T ~string](name string, values ...T) *Enum[T] {
+	return &Enum[T]{name: name, values: values}
+}
+
+func (e *Enum[T]) Name() string {
+	return e.name
+}
+
+func (e *Enum[T]) Values() []T {
+	return slices.Clone(e.values)
+}
+
+func (e *Enum[T]) Labels() []string {
+	labels := make([]string, len(e.values))
+	for i, v := range e.values {
+		labels[i] = string(v)
+	}
+
+	return labels
+}
+
+func (e *Enum[T]) Valid(v T) bool {
+	return slices.Contains(e.values, v)
+}
+
+func (e *Enum[T]) Parse(s string) (T, error) {
+	v := T(s)
+	if !e.Valid(v) {
+		return "", fmt.Errorf("%w: %q is not a %s", ErrInvalidEnumValue, s, e.name)
+	}
+
+	return v, nil
+}
+
+func RequiredEnum[T ~string](t pgtype.Text, e *Enum[T]) (T, error) {
+	if !t.Valid {
+		panic(fmt.Sprintf("%s is required", e.name))
+	}
+
+	return e.Parse(t.String)
+}
+
+func TryEnum[T ~string](t pgtype.Text, e *Enum[T]) (T, error) {
+	if !t.Valid {
+		return "", unexpectedNull(e.name)
+	}
+
+	return e.Parse(t.String)
+}
+
+func NilableEnum[T ~string](t pgtype.Text, e *Enum[T]) (nilable.Value[T], error) {
+	if !t.Valid {
+		return nilable.NilValue[T](), nil
+	}
+
+	val, err := e.Parse(t.String)
+	if err != nil {
+		return nilable.InvalidValue[T](), err
+	}
+
+	return nilable.NewValue(&val), nil
+}
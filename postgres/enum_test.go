@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/assert"
+)
+
+type mood string
+
+const (
+	moodHappy mood = "happy"
+	moodSad   mood = "sad"
+)
+
+var moodEnum = NewEnum("mood", moodHappy, moodSad)
+
+func TestEnum(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "mood", moodEnum.Name())
+	assert.Equal(t, []mood{moodHappy, moodSad}, moodEnum.Values())
+	assert.Equal(t, []string{"happy", "sad"}, moodEnum.Labels())
+	assert.True(t, moodEnum.Valid(moodHappy))
+	assert.False(t, moodEnum.Valid("angry"))
+}
+
+func TestEnumParse(t *testing.T) {
+	t.Parallel()
+
+	v, err := moodEnum.Parse("sad")
+	assert.NoError(t, err)
+	assert.Equal(t, moodSad, v)
+
+	_, err = moodEnum.Parse("angry")
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+	assert.EqualError(t, err, `postgres enum: invalid value: "angry" is not a mood`)
+}
+
+func TestRequiredEnum(t *testing.T) {
+	t.Parallel()
+
+	v, err := RequiredEnum(pgtype.Text{String: "happy", Valid: true}, moodEnum)
+	assert.NoError(t, err)
+	assert.Equal(t, moodHappy, v)
+
+	_, err = RequiredEnum(pgtype.Text{String: "angry", Valid: true}, moodEnum)
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+
+	assert.Panics(t, func() { RequiredEnum(pgtype.Text{}, moodEnum) })
+}
+
+func TestTryEnum(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryEnum(pgtype.Text{String: "happy", Valid: true}, moodEnum)
+	assert.NoError(t, err)
+	assert.Equal(t, moodHappy, v)
+
+	_, err = TryEnum(pgtype.Text{String: "angry", Valid: true}, moodEnum)
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+
+	_, err = TryEnum(pgtype.Text{}, moodEnum)
+	assert.EqualError(t, err, "unexpected null: mood")
+}
+
+func TestNilableEnum(t *testing.T) {
+	t.Parallel()
+
+	v, err := NilableEnum(pgtype.Text{String: "sad", Valid: true}, moodEnum)
+	assert.NoError(t, err)
+	assert.Equal(t, moodSad, *v.Value)
+
+	v, err = NilableEnum(pgtype.Text{}, moodEnum)
+	assert.NoError(t, err)
+	assert.True(t, v.Valid)
+	assert.Nil(t, v.Value)
+
+	v, err = NilableEnum(pgtype.Text{String: "angry", Valid: true}, moodEnum)
+	assert.ErrorIs(t, err, ErrInvalidEnumValue)
+	assert.False(t, v.Valid)
+}
@@ -2,8 +2,10 @@ package postgres
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jeremybower/go-common"
 )
 
@@ -12,5 +14,10 @@ func NormalizeError(err error) error {
 		return common.ErrNotFound
 	}
 
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "55P03" {
+		return fmt.Errorf("%w: %w", ErrLockNotAvailable, err)
+	}
+
 	return err
 }
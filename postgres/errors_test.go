@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jeremybower/go-common"
 	"github.com/stretchr/testify/assert"
 )
@@ -13,4 +14,9 @@ func TestNormalizeError(t *testing.T) {
 
 	assert.Equal(t, common.ErrNotFound, NormalizeError(pgx.ErrNoRows))
 	assert.Equal(t, assert.AnError, NormalizeError(assert.AnError))
+
+	lockErr := &pgconn.PgError{Code: "55P03"}
+	err := NormalizeError(lockErr)
+	assert.ErrorIs(t, err, ErrLockNotAvailable)
+	assert.ErrorIs(t, err, lockErr)
 }
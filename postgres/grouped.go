@@ -0,0 +1,165 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/georgysavva/scany/v2/dbscan"
+	"github.com/georgysavva/scany/v2/pgxscan"
+	"github.com/jackc/pgx/v5"
+)
+
+// ChildColumnPrefix marks the columns that ReadGrouped scans into the child.
+const ChildColumnPrefix = "child."
+
+var groupedScanAPI = func() *dbscan.API {
+	api, err := pgxscan.NewDBScanAPI()
+	if err != nil {
+		panic(err)
+	}
+
+	return api
+}()
+
+func ReadGroupedT[P any, C any, K comparable](
+	ctx context.Context,
+	querier Querier,
+	key func(p *P) K,
+	add func(p *P, c *C),
+	templ *Template,
+	data map[string]any,
+) ([]*P, error) {
+	// Execute the template to build the SQL.
+	ctx = withTemplateName(ctx, templ)
+	sql, args, err := templ.ExecuteContext(ctx, data)
+	if err != nil {
+		return nil, NormalizeError(err)
+	}
+
+	// Use the prepared statement when available.
+	querier, sql, release, err := prepared(ctx, querier, templ, sql)
+	if err != nil {
+		return nil, NormalizeError(err)
+	}
+	defer release()
+
+	// Success.
+	return ReadGrouped(ctx, querier, key, add, sql, args...)
+}
+
+// ReadGrouped reads a one-to-many join, folding the child of each row into
+// its parent. Columns named with ChildColumnPrefix, such as
+// `item.id AS "child.id"`, are scanned into C and the rest into P. Parents
+// are returned in the order they first appear and rows whose child columns
+// are all NULL, as from a LEFT JOIN without a match, add no child.
+func ReadGrouped[P any, C any, K comparable](
+	ctx context.Context,
+	querier Querier,
+	key func(p *P) K,
+	add func(p *P, c *C),
+	sql string,
+	args ...any,
+) ([]*P, error) {
+	// Query the rows.
+	rows, err := querier.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, NormalizeError(err)
+	}
+	defer rows.Close()
+
+	// Split the columns between the parent and the child.
+	parentRows := &columnRows{Rows: rows}
+	childRows := &columnRows{Rows: rows}
+	for i, field := range rows.FieldDescriptions() {
+		if name, ok := strings.CutPrefix(field.Name, ChildColumnPrefix); ok {
+			childRows.add(i, name)
+		} else {
+			parentRows.add(i, field.Name)
+		}
+	}
+
+	parentScanner := groupedScanAPI.NewRowScanner(parentRows)
+	childScanner := groupedScanAPI.NewRowScanner(childRows)
+
+	// Fold the rows.
+	var parents []*P
+	parentsByKey := make(map[K]*P)
+	for rows.Next() {
+		var parent P
+		if err := parentScanner.Scan(&parent); err != nil {
+			return nil, NormalizeError(err)
+		}
+
+		k := key(&parent)
+		p, ok := parentsByKey[k]
+		if !ok {
+			p = &parent
+			parentsByKey[k] = p
+			parents = append(parents, p)
+		}
+
+		if childRows.null() {
+			continue
+		}
+
+		var child C
+		if err := childScanner.Scan(&child); err != nil {
+			return nil, NormalizeError(err)
+		}
+
+		add(p, &child)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, NormalizeError(err)
+	}
+
+	// Success.
+	return parents, nil
+}
+
+// columnRows presents a subset of the columns of the current row to dbscan.
+type columnRows struct {
+	pgx.Rows
+	indexes []int
+	names   []string
+}
+
+func (r *columnRows) add(index int, name string) {
+	r.indexes = append(r.indexes, index)
+	r.names = append(r.names, name)
+}
+
+func (r *columnRows) null() bool {
+	values := r.RawValues()
+	for _, i := range r.indexes {
+		if values[i] != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *columnRows) Close() error {
+	r.Rows.Close()
+	return nil
+}
+
+func (r *columnRows) Columns() ([]string, error) {
+	return r.names, nil
+}
+
+func (r *columnRows) NextResultSet() bool {
+	return false
+}
+
+// Scan scans into the subset of columns, skipping the others.
+func (r *columnRows) Scan(dest ...any) error {
+	all := make([]any, len(r.FieldDescriptions()))
+	for i, index := range r.indexes {
+		all[index] = dest[i]
+	}
+
+	return r.Rows.Scan(all...)
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type groupedOrder struct {
+	ID    int64              `db:"id"`
+	Name  string             `db:"name"`
+	Items []groupedOrderItem `db:"items"`
+}
+
+type groupedOrderItem struct {
+	ID      int64  `db:"id" json:"id"`
+	Product string `db:"product" json:"product"`
+}
+
+func groupedOrdersForTesting(t *testing.T) *pgxpool.Pool {
+	dbPool := databasePoolForTesting(t)
+
+	_, err := dbPool.Exec(context.Background(), `
+		CREATE TABLE orders (id BIGINT PRIMARY KEY, name TEXT NOT NULL);
+		CREATE TABLE order_items (id BIGINT PRIMARY KEY, order_id BIGINT NOT NULL REFERENCES orders, product TEXT NOT NULL);
+		INSERT INTO orders (id, name) VALUES (1, 'first'), (2, 'second'), (3, 'empty');
+		INSERT INTO order_items (id, order_id, product) VALUES (1, 1, 'apple'), (2, 2, 'banana'), (3, 1, 'cherry');
+	`)
+	require.NoError(t, err)
+
+	return dbPool
+}
+
+func TestReadGrouped(t *testing.T) {
+	t.Parallel()
+
+	dbPool := groupedOrdersForTesting(t)
+	defer dbPool.Close()
+
+	orders, err := ReadGrouped(
+		context.Background(),
+		dbPool,
+		func(o *groupedOrder) int64 { return o.ID },
+		func(o *groupedOrder, i *groupedOrderItem) { o.Items = append(o.Items, *i) },
+		`SELECT o.id, o.name, i.id AS "child.id", i.product AS "child.product"
+		FROM orders o LEFT JOIN order_items i ON i.order_id = o.id
+		ORDER BY o.id, i.id`,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []*groupedOrder{
+		{ID: 1, Name: "first", Items: []groupedOrderItem{{ID: 1, Product: "apple"}, {ID: 3, Product: "cherry"}}},
+		{ID: 2, Name: "second", Items: []groupedOrderItem{{ID: 2, Product: "banana"}}},
+		{ID: 3, Name: "empty"},
+	}, orders)
+}
+
+func TestReadGroupedT(t *testing.T) {
+	t.Parallel()
+
+	dbPool := groupedOrdersForTesting(t)
+	defer dbPool.Close()
+
+	templ := MustParse(`SELECT o.id, o.name, i.id AS "child.id", i.product AS "child.product"
+		FROM orders o JOIN order_items i ON i.order_id = o.id
+		WHERE o.id = {{ arg .ID }}
+		ORDER BY i.id`)
+
+	orders, err := ReadGroupedT(
+		context.Background(),
+		dbPool,
+		func(o *groupedOrder) int64 { return o.ID },
+		func(o *groupedOrder, i *groupedOrderItem) { o.Items = append(o.Items, *i) },
+		templ,
+		map[string]any{"ID": 1},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []*groupedOrder{
+		{ID: 1, Name: "first", Items: []groupedOrderItem{{ID: 1, Product: "apple"}, {ID: 3, Product: "cherry"}}},
+	}, orders)
+}
+
+func TestReadManyJSONAgg(t *testing.T) {
+	t.Parallel()
+
+	dbPool := groupedOrdersForTesting(t)
+	defer dbPool.Close()
+
+	templ := MustParse(`SELECT o.id, o.name, {{ jsonAgg "i ORDER BY i.id" "i.id" }} AS items
+		FROM orders o LEFT JOIN order_items i ON i.order_id = o.id
+		GROUP BY o.id
+		ORDER BY o.id`)
+
+	orders, err := ReadManyT[groupedOrder](context.Background(), dbPool, templ, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*groupedOrder{
+		{ID: 1, Name: "first", Items: []groupedOrderItem{{ID: 1, Product: "apple"}, {ID: 3, Product: "cherry"}}},
+		{ID: 2, Name: "second", Items: []groupedOrderItem{{ID: 2, Product: "banana"}}},
+		{ID: 3, Name: "empty", Items: []groupedOrderItem{}},
+	}, orders)
+}
@@ -0,0 +1,187 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jeremybower/go-common"
+	"github.com/jeremybower/go-common/postgres"
+)
+
+var ErrIdempotency = errors.New("postgres idempotency")
+var ErrInProgress = fmt.Errorf("%w: request in progress", ErrIdempotency)
+var ErrFingerprintMismatch = fmt.Errorf("%w: key reused for a different request", ErrIdempotency)
+
+// Migration creates the idempotency_keys table.
+const Migration = `
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+	key TEXT PRIMARY KEY,
+	fingerprint TEXT NOT NULL,
+	token TEXT NOT NULL,
+	status_code INT,
+	header JSONB,
+	body BYTEA,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	completed_at TIMESTAMPTZ,
+	locked_until TIMESTAMPTZ NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+`
+
+type Response struct {
+	StatusCode int32
+	Header     http.Header
+	Body       []byte
+}
+
+// Store records idempotency keys in Postgres so that a request is processed
+// once across every replica. Keys expire after the ttl, after which they can
+// be claimed again and are removed by Purge. A claim that is neither
+// completed nor released within the lease, such as when the process crashed,
+// can be claimed again by the same request, so the lease should be longer
+// than the request takes and shorter than the ttl.
+type Store struct {
+	querier postgres.Querier
+	ttl     time.Duration
+	lease   time.Duration
+}
+
+func NewStore(querier postgres.Querier, ttl time.Duration, lease time.Duration) *Store {
+	return &Store{querier: querier, ttl: ttl, lease: lease}
+}
+
+type record struct {
+	Fingerprint string      `db:"fingerprint"`
+	CompletedAt *time.Time  `db:"completed_at"`
+	StatusCode  *int32      `db:"status_code"`
+	Header      http.Header `db:"header"`
+	Body        []byte      `db:"body"`
+}
+
+// beginAttempts bounds how many times Begin claims a key that is released
+// or purged between the claim and the read of its holder.
+const beginAttempts = 3
+
+// Begin claims the key for a request. When the key was claimed and the
+// request should be processed, it returns a token for Complete or Release
+// and a nil response. It returns the stored response when the request was
+// already processed, ErrInProgress while another request holds the key and
+// ErrFingerprintMismatch when the key was used for a different request.
+// ErrInProgress is also returned when the key keeps changing hands.
+func (s *Store) Begin(ctx context.Context, key string, fingerprint string) (string, *Response, error) {
+	for attempt := 0; attempt < beginAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return "", nil, err
+		}
+
+		token, stored, err := s.begin(ctx, key, fingerprint)
+		if errors.Is(err, common.ErrNotFound) {
+			// The key was released or purged in the meantime.
+			continue
+		}
+
+		return token, stored, err
+	}
+
+	return "", nil, ErrInProgress
+}
+
+// begin makes one attempt to claim the key, returning common.ErrNotFound
+// when the key was released or purged after the claim failed.
+func (s *Store) begin(ctx context.Context, key string, fingerprint string) (string, *Response, error) {
+	// Claim the key unless it is held and unexpired, or its lease has
+	// expired for the same request.
+	token := uuid.NewString()
+	claimed, err := postgres.Exec(ctx, s.querier, `
+		INSERT INTO idempotency_keys (key, fingerprint, token, locked_until, expires_at)
+		VALUES ($1, $2, $3, now() + $4::interval, now() + $5::interval)
+		ON CONFLICT (key) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			token = EXCLUDED.token,
+			status_code = NULL,
+			header = NULL,
+			body = NULL,
+			created_at = now(),
+			completed_at = NULL,
+			locked_until = EXCLUDED.locked_until,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+			OR (idempotency_keys.completed_at IS NULL
+				AND idempotency_keys.locked_until <= now()
+				AND idempotency_keys.fingerprint = EXCLUDED.fingerprint)`,
+		key, fingerprint, token, s.lease, s.ttl,
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if claimed == 1 {
+		return token, nil, nil
+	}
+
+	// Read the holder of the key.
+	r, err := postgres.ReadOne[record](ctx, s.querier, `
+		SELECT fingerprint, completed_at, status_code, header, body
+		FROM idempotency_keys
+		WHERE key = $1`,
+		key,
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if r.Fingerprint != fingerprint {
+		return "", nil, ErrFingerprintMismatch
+	}
+
+	if r.CompletedAt == nil || r.StatusCode == nil {
+		return "", nil, ErrInProgress
+	}
+
+	// Success.
+	return "", &Response{StatusCode: *r.StatusCode, Header: r.Header, Body: r.Body}, nil
+}
+
+// Complete stores the response of a claimed key, which Begin returns for
+// later requests with the key. It fails with common.ErrNotFound unless the
+// token still holds the key.
+func (s *Store) Complete(ctx context.Context, key string, token string, response Response) error {
+	c, err := postgres.Exec(ctx, s.querier, `
+		UPDATE idempotency_keys
+		SET status_code = $3, header = $4, body = $5, completed_at = now()
+		WHERE key = $1 AND token = $2 AND completed_at IS NULL`,
+		key, token, response.StatusCode, response.Header, response.Body,
+	)
+	if err != nil {
+		return err
+	}
+
+	if c == 0 {
+		return common.ErrNotFound
+	}
+
+	// Success.
+	return nil
+}
+
+// Release gives up a claimed key without storing a response so that the
+// request can be retried, such as after a failure that made no changes. It
+// does nothing unless the token still holds the key.
+func (s *Store) Release(ctx context.Context, key string, token string) error {
+	_, err := postgres.Exec(ctx, s.querier,
+		"DELETE FROM idempotency_keys WHERE key = $1 AND token = $2 AND completed_at IS NULL",
+		key, token,
+	)
+	return err
+}
+
+// Purge removes the expired keys, returning how many were removed.
+func (s *Store) Purge(ctx context.Context) (int64, error) {
+	return postgres.Exec(ctx, s.querier, "DELETE FROM idempotency_keys WHERE expires_at <= now()")
+}
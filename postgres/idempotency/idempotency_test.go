@@ -0,0 +1,155 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common"
+	"github.com/jeremybower/go-common/internal/pgtest"
+	"github.com/jeremybower/go-common/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func databasePoolForTesting(t *testing.T) *pgxpool.Pool {
+	return pgtest.Pool(t, Migration)
+}
+
+func TestStoreBeginCanceled(t *testing.T) {
+	t.Parallel()
+
+	// The context is checked before the store is used.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := NewStore(nil, time.Hour, time.Minute).Begin(ctx, "key", "a")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	s := NewStore(dbPool, time.Hour, time.Minute)
+
+	// The first request claims the key.
+	token, stored, err := s.Begin(ctx, "key", "a")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token)
+	assert.Nil(t, stored)
+
+	// Duplicates conflict until the response is stored.
+	_, _, err = s.Begin(ctx, "key", "a")
+	assert.ErrorIs(t, err, ErrInProgress)
+
+	_, _, err = s.Begin(ctx, "key", "b")
+	assert.ErrorIs(t, err, ErrFingerprintMismatch)
+
+	// Only the holder of the key can complete it.
+	response := Response{StatusCode: 201, Header: http.Header{"Location": {"/payments/1"}}, Body: []byte("created")}
+	assert.ErrorIs(t, s.Complete(ctx, "key", "other", response), common.ErrNotFound)
+	require.NoError(t, s.Complete(ctx, "key", token, response))
+
+	// Replays return the stored response.
+	_, stored, err = s.Begin(ctx, "key", "a")
+	require.NoError(t, err)
+	assert.Equal(t, &response, stored)
+
+	// A completed key cannot be completed or released again.
+	assert.ErrorIs(t, s.Complete(ctx, "key", token, response), common.ErrNotFound)
+	require.NoError(t, s.Release(ctx, "key", token))
+	_, stored, err = s.Begin(ctx, "key", "a")
+	require.NoError(t, err)
+	assert.Equal(t, &response, stored)
+}
+
+func TestStoreRelease(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	s := NewStore(dbPool, time.Hour, time.Minute)
+
+	token, _, err := s.Begin(ctx, "key", "a")
+	require.NoError(t, err)
+
+	// Only the holder of the key can release it.
+	require.NoError(t, s.Release(ctx, "key", "other"))
+	_, _, err = s.Begin(ctx, "key", "a")
+	assert.ErrorIs(t, err, ErrInProgress)
+
+	require.NoError(t, s.Release(ctx, "key", token))
+	_, stored, err := s.Begin(ctx, "key", "b")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestStoreLease(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	s := NewStore(dbPool, time.Hour, time.Minute)
+
+	token, _, err := s.Begin(ctx, "key", "a")
+	require.NoError(t, err)
+	_, err = postgres.Exec(ctx, dbPool, "UPDATE idempotency_keys SET locked_until = now() - interval '1 second' WHERE key = 'key'")
+	require.NoError(t, err)
+
+	// A different request cannot take over an abandoned claim.
+	_, _, err = s.Begin(ctx, "key", "b")
+	assert.ErrorIs(t, err, ErrFingerprintMismatch)
+
+	// The same request can, and the abandoned claim can no longer complete.
+	retried, stored, err := s.Begin(ctx, "key", "a")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+	assert.NotEqual(t, token, retried)
+
+	response := Response{StatusCode: 201}
+	assert.ErrorIs(t, s.Complete(ctx, "key", token, response), common.ErrNotFound)
+	require.NoError(t, s.Complete(ctx, "key", retried, response))
+}
+
+func TestStoreExpiry(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	s := NewStore(dbPool, time.Hour, time.Minute)
+
+	_, _, err := s.Begin(ctx, "expired", "a")
+	require.NoError(t, err)
+	_, _, err = s.Begin(ctx, "current", "a")
+	require.NoError(t, err)
+	_, err = postgres.Exec(ctx, dbPool, "UPDATE idempotency_keys SET expires_at = now() - interval '1 second' WHERE key = 'expired'")
+	require.NoError(t, err)
+
+	// An expired key can be claimed again.
+	_, stored, err := s.Begin(ctx, "expired", "b")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Expired keys are purged.
+	_, err = postgres.Exec(ctx, dbPool, "UPDATE idempotency_keys SET expires_at = now() - interval '1 second' WHERE key = 'expired'")
+	require.NoError(t, err)
+
+	c, err := s.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	_, _, err = s.Begin(ctx, "current", "a")
+	assert.ErrorIs(t, err, ErrInProgress)
+}
@@ -0,0 +1,166 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/jeremybower/go-common"
+)
+
+// HeaderKey is the request header that holds the idempotency key.
+const HeaderKey = "Idempotency-Key"
+
+// HeaderReplayed is set on responses that were replayed from the store.
+const HeaderReplayed = "Idempotent-Replayed"
+
+// Middleware processes each request with an idempotency key once. Later
+// requests with the key replay the stored response, concurrent requests get
+// 409 Conflict and requests that reuse the key with a different method, path
+// or body get 422 Unprocessable Entity. Server errors are not stored, so the
+// request can be retried. Requests without a key are passed through.
+// Failures to store or release a key are logged with the context's logger,
+// when set, and the key can be claimed again once its lease expires.
+//
+// Request bodies larger than maxBodyBytes get 413 Request Entity Too Large.
+// Keys are namespaced by the scope of the request, such as the
+// authenticated subject or tenant, so that a caller cannot replay the
+// response to another caller's request. A nil scope puts every request in
+// the same namespace.
+func Middleware(store *Store, maxBodyBytes int64, scope func(r *http.Request) string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Fingerprint the request.
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			} else if err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			s := ""
+			if scope != nil {
+				s = scope(r)
+			}
+			key = scopedKey(s, key)
+
+			// Claim the key.
+			ctx := r.Context()
+			token, stored, err := store.Begin(ctx, key, fingerprint(s, r, body))
+			switch {
+			case errors.Is(err, ErrInProgress):
+				http.Error(w, http.StatusText(http.StatusConflict), http.StatusConflict)
+				return
+			case errors.Is(err, ErrFingerprintMismatch):
+				http.Error(w, http.StatusText(http.StatusUnprocessableEntity), http.StatusUnprocessableEntity)
+				return
+			case err != nil:
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+
+			// Replay the stored response.
+			if stored != nil {
+				for name, values := range stored.Header {
+					w.Header()[name] = values
+				}
+				w.Header().Set(HeaderReplayed, "true")
+				w.WriteHeader(int(stored.StatusCode))
+				_, _ = w.Write(stored.Body)
+				return
+			}
+
+			// Process the request. The key is released when the handler
+			// fails or panics, but kept until its lease expires when the
+			// response cannot be stored so that retries are not processed
+			// while the failure may be transient.
+			ctx = context.WithoutCancel(ctx)
+			rec := &recorder{ResponseWriter: w, statusCode: http.StatusOK}
+			processed := false
+			defer func() {
+				if !processed {
+					if err := store.Release(ctx, key, token); err != nil {
+						logError(ctx, "failed to release idempotency key", key, err)
+					}
+				}
+			}()
+
+			next.ServeHTTP(rec, r)
+			if rec.statusCode >= 500 {
+				return
+			}
+
+			processed = true
+			if err := store.Complete(ctx, key, token, Response{
+				StatusCode: int32(rec.statusCode),
+				Header:     w.Header().Clone(),
+				Body:       rec.body.Bytes(),
+			}); err != nil {
+				logError(ctx, "failed to store idempotent response", key, err)
+			}
+		})
+	}
+}
+
+func logError(ctx context.Context, msg string, key string, err error) {
+	if logger, loggerErr := common.Logger(ctx); loggerErr == nil {
+		logger.ErrorContext(ctx, msg, "key", key, "error", err)
+	}
+}
+
+// scopedKey prefixes the key with the length of the scope so that no two
+// scopes and keys combine into the same stored key.
+func scopedKey(scope string, key string) string {
+	return strconv.Itoa(len(scope)) + ":" + scope + ":" + key
+}
+
+// fingerprint identifies a request by its scope, method, path and body.
+func fingerprint(scope string, r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(scope))
+	h.Write([]byte{0})
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.RequestURI()))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recorder writes the response through while keeping a copy to store.
+type recorder struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(statusCode int) {
+	if !r.wroteHeader {
+		r.statusCode = statusCode
+		r.wroteHeader = true
+	}
+
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
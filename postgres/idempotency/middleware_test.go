@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareWithoutKey(t *testing.T) {
+	t.Parallel()
+
+	// The store is not used without a key.
+	h := Middleware(nil, 1024, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("POST", "/payments", nil))
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+
+	r := httptest.NewRequest("POST", "/payments?a=1", nil)
+	assert.Equal(t, fingerprint("alice", r, []byte("body")), fingerprint("alice", r, []byte("body")))
+	assert.NotEqual(t, fingerprint("alice", r, []byte("body")), fingerprint("alice", r, []byte("other")))
+	assert.NotEqual(t, fingerprint("alice", r, nil), fingerprint("bob", r, nil))
+	assert.NotEqual(t, fingerprint("alice", r, nil), fingerprint("alice", httptest.NewRequest("PUT", "/payments?a=1", nil), nil))
+	assert.NotEqual(t, fingerprint("alice", r, nil), fingerprint("alice", httptest.NewRequest("POST", "/payments?a=2", nil), nil))
+}
+
+func TestScopedKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "5:alice:key", scopedKey("alice", "key"))
+	assert.Equal(t, "0::key", scopedKey("", "key"))
+	assert.NotEqual(t, scopedKey("a:b", "c"), scopedKey("a", "b:c"))
+}
+
+func TestMiddlewareBodyTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// The body is rejected before the store is used.
+	h := Middleware(nil, 4, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called")
+	}))
+
+	r := httptest.NewRequest("POST", "/payments", strings.NewReader("too large"))
+	r.Header.Set(HeaderKey, "key")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	var calls atomic.Int32
+	scope := func(r *http.Request) string { return r.Header.Get("X-Tenant") }
+	h := Middleware(NewStore(dbPool, time.Hour, time.Minute), 1024, scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Location", "/payments/1")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created"))
+	}))
+
+	requestAs := func(tenant string, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/payments", strings.NewReader(body))
+		r.Header.Set(HeaderKey, "key")
+		r.Header.Set("X-Tenant", tenant)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec
+	}
+
+	request := func(body string) *httptest.ResponseRecorder {
+		return requestAs("alice", body)
+	}
+
+	// A server error is not stored.
+	rec := request("pay")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// The retry is processed.
+	rec = request("pay")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "created", rec.Body.String())
+	assert.Empty(t, rec.Header().Get(HeaderReplayed))
+
+	// Later requests are replayed.
+	rec = request("pay")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "created", rec.Body.String())
+	assert.Equal(t, "/payments/1", rec.Header().Get("Location"))
+	assert.Equal(t, "true", rec.Header().Get(HeaderReplayed))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// A different request with the key is rejected.
+	rec = request("refund")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	// Another tenant's request with the key is processed, not replayed.
+	rec = requestAs("bob", "pay")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Empty(t, rec.Header().Get(HeaderReplayed))
+	assert.Equal(t, int32(3), calls.Load())
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSONB holds a json or jsonb column decoded into T. It scans NULL as
+// invalid and encodes an invalid value as NULL.
+type JSONB[T any] struct {
+	Data  T
+	Valid bool
+}
+
+func NewJSONB[T any](data T) JSONB[T] {
+	return JSONB[T]{Data: data, Valid: true}
+}
+
+func (j *JSONB[T]) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case nil:
+		var zero T
+		j.Data = zero
+		j.Valid = false
+		return nil
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return fmt.Errorf("cannot scan %T into %T", src, j)
+	}
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	j.Data = v
+	j.Valid = true
+	return nil
+}
+
+func (j JSONB[T]) Value() (driver.Value, error) {
+	if !j.Valid {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(j.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+func (j JSONB[T]) MarshalJSON() ([]byte, error) {
+	if !j.Valid {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(j.Data)
+}
+
+func (j *JSONB[T]) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		var zero T
+		j.Data = zero
+		j.Valid = false
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &j.Data); err != nil {
+		return err
+	}
+
+	j.Valid = true
+	return nil
+}
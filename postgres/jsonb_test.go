@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type jsonbItem struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func TestJSONBScan(t *testing.T) {
+	t.Parallel()
+
+	var j JSONB[jsonbItem]
+	require.NoError(t, j.Scan([]byte(`{"name":"a","tags":["x"]}`)))
+	assert.Equal(t, NewJSONB(jsonbItem{Name: "a", Tags: []string{"x"}}), j)
+
+	require.NoError(t, j.Scan(`{"name":"b"}`))
+	assert.Equal(t, NewJSONB(jsonbItem{Name: "b"}), j)
+
+	require.NoError(t, j.Scan(nil))
+	assert.Equal(t, JSONB[jsonbItem]{}, j)
+
+	assert.Error(t, j.Scan(123))
+	assert.Error(t, j.Scan(`{"name":`))
+}
+
+func TestJSONBValue(t *testing.T) {
+	t.Parallel()
+
+	v, err := NewJSONB([]int{1, 2}).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "[1,2]", v)
+
+	v, err = JSONB[[]int]{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
+
+func TestJSONBMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	b, err := json.Marshal(NewJSONB(jsonbItem{Name: "a"}))
+	require.NoError(t, err)
+	assert.Equal(t, `{"name":"a","tags":null}`, string(b))
+
+	b, err = json.Marshal(JSONB[jsonbItem]{})
+	require.NoError(t, err)
+	assert.Equal(t, `null`, string(b))
+
+	var j JSONB[jsonbItem]
+	require.NoError(t, json.Unmarshal([]byte(`{"name":"a"}`), &j))
+	assert.Equal(t, NewJSONB(jsonbItem{Name: "a"}), j)
+
+	require.NoError(t, json.Unmarshal([]byte(`null`), &j))
+	assert.Equal(t, JSONB[jsonbItem]{}, j)
+}
+
+func TestJSONBRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := dbPool.Exec(ctx, "CREATE TABLE documents (id BIGSERIAL PRIMARY KEY, item JSONB, items JSON);")
+	require.NoError(t, err)
+
+	type documentRow struct {
+		ID    int64              `db:"id"`
+		Item  JSONB[jsonbItem]   `db:"item"`
+		Items JSONB[[]jsonbItem] `db:"items"`
+	}
+
+	item := jsonbItem{Name: "a", Tags: []string{"x", "y"}}
+	_, err = Exec(ctx, dbPool, "INSERT INTO documents (item, items) VALUES ($1, $2), ($3, $4);",
+		NewJSONB(item), NewJSONB([]jsonbItem{item}),
+		JSONB[jsonbItem]{}, JSONB[[]jsonbItem]{},
+	)
+	require.NoError(t, err)
+
+	rows, err := ReadMany[documentRow](ctx, dbPool, "SELECT * FROM documents ORDER BY id;")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, item, RequiredJSONB(rows[0].Item))
+	assert.Equal(t, []jsonbItem{item}, RequiredJSONB(rows[0].Items))
+	assert.False(t, rows[1].Item.Valid)
+	assert.False(t, rows[1].Items.Valid)
+
+	templ := MustParse(`SELECT * FROM documents WHERE {{ jsonbContains "item" .Filter }}`)
+	rows, err = ReadManyT[documentRow](ctx, dbPool, templ, map[string]any{"Filter": map[string]any{"tags": []string{"y"}}})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, rows[0].Item.Data, item)
+}
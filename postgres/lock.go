@@ -0,0 +1,199 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrLock = errors.New("postgres lock")
+var ErrLockNotAvailable = fmt.Errorf("%w: not available", ErrLock)
+var ErrLockNotInTx = fmt.Errorf("%w: must be acquired in a transaction", ErrLock)
+var ErrInvalidLock = fmt.Errorf("%w: invalid", ErrLock)
+var ErrLockMissing = fmt.Errorf("%w: no locking clause", ErrLock)
+
+type LockStrength string
+
+const (
+	ForUpdate      LockStrength = "FOR UPDATE"
+	ForNoKeyUpdate LockStrength = "FOR NO KEY UPDATE"
+	ForShare       LockStrength = "FOR SHARE"
+	ForKeyShare    LockStrength = "FOR KEY SHARE"
+)
+
+// LockWait chooses what happens when a row is already locked. By default,
+// the query waits for the lock.
+type LockWait string
+
+const (
+	LockWaitDefault LockWait = ""
+	NoWait          LockWait = "NOWAIT"
+	SkipLocked      LockWait = "SKIP LOCKED"
+)
+
+// Lock is a row-level locking clause. NoWait fails with ErrLockNotAvailable
+// when a row is locked and SkipLocked leaves locked rows out of the result.
+type Lock struct {
+	Strength LockStrength
+	Wait     LockWait
+}
+
+func (l Lock) Clause() (string, error) {
+	switch l.Strength {
+	case ForUpdate, ForNoKeyUpdate, ForShare, ForKeyShare:
+	default:
+		return "", fmt.Errorf("%w: strength %q", ErrInvalidLock, l.Strength)
+	}
+
+	switch l.Wait {
+	case LockWaitDefault:
+		return string(l.Strength), nil
+	case NoWait, SkipLocked:
+		return string(l.Strength) + " " + string(l.Wait), nil
+	default:
+		return "", fmt.Errorf("%w: wait %q", ErrInvalidLock, l.Wait)
+	}
+}
+
+// ReadOneForUpdateT reads a row with a template that locks it, such as with
+// `{{ forUpdate }}`, and fails with ErrLockMissing when the template has no
+// locking clause. The querier must be a transaction or wrap one.
+func ReadOneForUpdateT[T any](
+	ctx context.Context,
+	querier Querier,
+	templ *Template,
+	data map[string]any,
+) (*T, error) {
+	// Execute the template and check the lock.
+	ctx = withTemplateName(ctx, templ)
+	sql, args, err := lockedTemplateSQL(ctx, querier, templ, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the prepared statement when available.
+	querier, sql, release, err := prepared(ctx, querier, templ, sql)
+	if err != nil {
+		return nil, NormalizeError(err)
+	}
+	defer release()
+
+	// Success.
+	return ReadOne[T](ctx, querier, sql, args...)
+}
+
+// ReadOneForUpdate reads a row, appending the lock to the SQL. The querier
+// must be a transaction, which holds the lock until it ends.
+func ReadOneForUpdate[T any](
+	ctx context.Context,
+	querier Querier,
+	lock Lock,
+	sql string,
+	args ...any,
+) (*T, error) {
+	// Add the lock.
+	sql, err := lockedSQL(querier, lock, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return ReadOne[T](ctx, querier, sql, args...)
+}
+
+// ReadManyForUpdateT is like ReadOneForUpdateT for many rows.
+func ReadManyForUpdateT[T any](
+	ctx context.Context,
+	querier Querier,
+	templ *Template,
+	data map[string]any,
+) ([]*T, error) {
+	// Execute the template and check the lock.
+	ctx = withTemplateName(ctx, templ)
+	sql, args, err := lockedTemplateSQL(ctx, querier, templ, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use the prepared statement when available.
+	querier, sql, release, err := prepared(ctx, querier, templ, sql)
+	if err != nil {
+		return nil, NormalizeError(err)
+	}
+	defer release()
+
+	// Success.
+	return ReadMany[T](ctx, querier, sql, args...)
+}
+
+// ReadManyForUpdate reads rows, appending the lock to the SQL. The querier
+// must be a transaction, which holds the locks until it ends.
+func ReadManyForUpdate[T any](
+	ctx context.Context,
+	querier Querier,
+	lock Lock,
+	sql string,
+	args ...any,
+) ([]*T, error) {
+	// Add the lock.
+	sql, err := lockedSQL(querier, lock, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return ReadMany[T](ctx, querier, sql, args...)
+}
+
+// lockedTemplateSQL executes a template that must render its own locking
+// clause, such as with `{{ forUpdate }}`.
+func lockedTemplateSQL(ctx context.Context, querier Querier, templ *Template, data map[string]any) (string, []any, error) {
+	if err := requireTx(querier); err != nil {
+		return "", nil, err
+	}
+
+	sql, args, err := templ.ExecuteContext(ctx, data)
+	if err != nil {
+		return "", nil, NormalizeError(err)
+	}
+
+	if !lockingClause.MatchString(sql) {
+		return "", nil, ErrLockMissing
+	}
+
+	// Success.
+	return sql, args, nil
+}
+
+func lockedSQL(querier Querier, lock Lock, sql string) (string, error) {
+	if err := requireTx(querier); err != nil {
+		return "", err
+	}
+
+	clause, err := lock.Clause()
+	if err != nil {
+		return "", err
+	}
+
+	// Success.
+	return strings.TrimRight(sql, " \t\r\n;") + " " + clause, nil
+}
+
+// requireTx fails unless the querier runs statements in a transaction,
+// either directly or through queriers that wrap one, because locks are
+// released at the end of the statement outside a transaction.
+func requireTx(querier Querier) error {
+	for {
+		switch q := querier.(type) {
+		case pgx.Tx:
+			return nil
+		case interface{ wrapped() Querier }:
+			querier = q.wrapped()
+		default:
+			return ErrLockNotInTx
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockClause(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		lock           Lock
+		expectedErr    error
+		expectedClause string
+	}{
+		{lock: Lock{Strength: ForUpdate}, expectedClause: "FOR UPDATE"},
+		{lock: Lock{Strength: ForNoKeyUpdate, Wait: NoWait}, expectedClause: "FOR NO KEY UPDATE NOWAIT"},
+		{lock: Lock{Strength: ForShare, Wait: SkipLocked}, expectedClause: "FOR SHARE SKIP LOCKED"},
+		{lock: Lock{Strength: ForKeyShare}, expectedClause: "FOR KEY SHARE"},
+		{lock: Lock{}, expectedErr: ErrInvalidLock},
+		{lock: Lock{Strength: "FOR UPDATE; DROP TABLE values"}, expectedErr: ErrInvalidLock},
+		{lock: Lock{Strength: ForUpdate, Wait: "WAIT"}, expectedErr: ErrInvalidLock},
+	}
+
+	for _, tt := range tests {
+		clause, err := tt.lock.Clause()
+		if tt.expectedErr != nil {
+			assert.ErrorIs(t, err, tt.expectedErr)
+		} else {
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedClause, clause)
+		}
+	}
+}
+
+func TestReadForUpdateNotInTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	q := &fakeQuerier{}
+	lock := Lock{Strength: ForUpdate}
+
+	_, err := ReadOneForUpdate[valueRow](ctx, q, lock, "SELECT * FROM values")
+	assert.ErrorIs(t, err, ErrLockNotInTx)
+
+	_, err = ReadManyForUpdate[valueRow](ctx, q, lock, "SELECT * FROM values")
+	assert.ErrorIs(t, err, ErrLockNotInTx)
+
+	templ := MustParse(`SELECT * FROM values {{ forUpdate }}`)
+	_, err = ReadOneForUpdateT[valueRow](ctx, q, templ, nil)
+	assert.ErrorIs(t, err, ErrLockNotInTx)
+
+	_, err = ReadManyForUpdateT[valueRow](ctx, q, templ, nil)
+	assert.ErrorIs(t, err, ErrLockNotInTx)
+
+	assert.Equal(t, 0, q.calls)
+}
+
+// fakeTx is a transaction for checks that fail before a statement is run.
+type fakeTx struct {
+	pgx.Tx
+}
+
+func TestReadForUpdateWrappedTx(t *testing.T) {
+	t.Parallel()
+
+	ctx := WithTenant(context.Background(), "tenant")
+	templ := MustParse(`SELECT * FROM values`)
+
+	// Queriers that wrap a transaction are accepted, so the missing lock is
+	// reported.
+	for _, q := range []Querier{
+		fakeTx{},
+		NewTenantQuerier(fakeTx{}),
+		NewRoutingQuerier(NewTenantQuerier(fakeTx{}), nil, 0, 0),
+	} {
+		_, err := ReadOneForUpdateT[valueRow](ctx, q, templ, nil)
+		assert.ErrorIs(t, err, ErrLockMissing)
+
+		_, err = ReadManyForUpdateT[valueRow](ctx, q, templ, nil)
+		assert.ErrorIs(t, err, ErrLockMissing)
+	}
+
+	// Queriers that wrap a pool are not.
+	_, err := ReadOneForUpdateT[valueRow](ctx, NewTenantQuerier(&fakeQuerier{}), templ, nil)
+	assert.ErrorIs(t, err, ErrLockNotInTx)
+}
+
+func TestReadForUpdate(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := Exec(ctx, dbPool, "INSERT INTO values (name, value) VALUES ('a', '1'), ('b', '2')")
+	require.NoError(t, err)
+
+	// Lock the first row.
+	tx, err := dbPool.Begin(ctx)
+	require.NoError(t, err)
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row, err := ReadOneForUpdate[valueRow](ctx, tx, Lock{Strength: ForUpdate}, "SELECT * FROM values WHERE name = $1;", "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", row.Name)
+
+	err = WithTx(ctx, dbPool, func(other pgx.Tx) error {
+		// A locked row is skipped.
+		rows, err := ReadManyForUpdate[valueRow](ctx, other, Lock{Strength: ForNoKeyUpdate, Wait: SkipLocked}, "SELECT * FROM values ORDER BY id")
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, "b", rows[0].Name)
+
+		// Or fails immediately.
+		templ := MustParse(`SELECT * FROM values WHERE name = {{ arg .Name }} {{ forUpdate "NOWAIT" }}`)
+		_, err = ReadOneForUpdateT[valueRow](ctx, other, templ, map[string]any{"Name": "a"})
+		assert.ErrorIs(t, err, ErrLockNotAvailable)
+		return nil
+	})
+	require.NoError(t, err)
+}
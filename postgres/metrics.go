@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the query
+// latency histogram.
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+const templateNameKey = contextKey("templateName")
+
+// withTemplateName records the template that a query was built from for
+// Metrics.
+func withTemplateName(ctx context.Context, templ *Template) context.Context {
+	return context.WithValue(ctx, templateNameKey, templ.Name())
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	template string
+	at       time.Time
+}
+
+// Metrics collects pool statistics and per-template query counts, errors
+// and latencies, and serves them in the Prometheus text exposition format.
+// It is a pgx.QueryTracer, such as for NewPoolFromEnv. Queries that are not
+// run from a named template are reported with an empty template label.
+type Metrics struct {
+	mu      sync.Mutex
+	pool    *pgxpool.Pool
+	buckets []float64
+	queries map[string]*queryMetrics
+	now     func() time.Time
+}
+
+type queryMetrics struct {
+	count   int64
+	errors  map[string]int64
+	buckets []int64
+	sum     float64
+}
+
+func NewMetrics(buckets []float64) *Metrics {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &Metrics{
+		buckets: buckets,
+		queries: make(map[string]*queryMetrics),
+		now:     time.Now,
+	}
+}
+
+// SetPool reports the statistics of the pool, which is usually created
+// after the metrics because they trace its queries.
+func (m *Metrics) SetPool(pool *pgxpool.Pool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.pool = pool
+}
+
+func (m *Metrics) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name, _ := ctx.Value(templateNameKey).(string)
+	return context.WithValue(ctx, queryStartKey{}, queryStart{template: name, at: m.now()})
+}
+
+func (m *Metrics) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+
+	seconds := m.now().Sub(start.at).Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	q, ok := m.queries[start.template]
+	if !ok {
+		q = &queryMetrics{
+			errors:  make(map[string]int64),
+			buckets: make([]int64, len(m.buckets)),
+		}
+		m.queries[start.template] = q
+	}
+
+	q.count++
+	q.sum += seconds
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			q.buckets[i]++
+		}
+	}
+
+	if data.Err != nil {
+		q.errors[sqlState(data.Err)]++
+	}
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.Write(w)
+}
+
+// Write writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) Write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := bufio.NewWriter(w)
+
+	// Pool statistics.
+	if m.pool != nil {
+		s := m.pool.Stat()
+		writeMetric(b, "postgres_pool_acquired_conns", "gauge", "Connections currently acquired from the pool.", s.AcquiredConns())
+		writeMetric(b, "postgres_pool_idle_conns", "gauge", "Idle connections in the pool.", s.IdleConns())
+		writeMetric(b, "postgres_pool_constructing_conns", "gauge", "Connections being established.", s.ConstructingConns())
+		writeMetric(b, "postgres_pool_total_conns", "gauge", "Connections in the pool.", s.TotalConns())
+		writeMetric(b, "postgres_pool_max_conns", "gauge", "Maximum connections in the pool.", s.MaxConns())
+		writeMetric(b, "postgres_pool_acquire_total", "counter", "Connections acquired from the pool.", s.AcquireCount())
+		writeMetric(b, "postgres_pool_acquire_duration_seconds_total", "counter", "Time spent acquiring connections.", s.AcquireDuration().Seconds())
+		writeMetric(b, "postgres_pool_empty_acquire_total", "counter", "Acquires that waited because the pool had no idle connection.", s.EmptyAcquireCount())
+		writeMetric(b, "postgres_pool_canceled_acquire_total", "counter", "Acquires that were canceled while waiting.", s.CanceledAcquireCount())
+		writeMetric(b, "postgres_pool_new_conns_total", "counter", "Connections opened.", s.NewConnsCount())
+		writeMetric(b, "postgres_pool_max_lifetime_destroy_total", "counter", "Connections closed for exceeding their maximum lifetime.", s.MaxLifetimeDestroyCount())
+		writeMetric(b, "postgres_pool_max_idle_destroy_total", "counter", "Connections closed for exceeding their maximum idle time.", s.MaxIdleDestroyCount())
+	}
+
+	// Query statistics.
+	templates := make([]string, 0, len(m.queries))
+	for name := range m.queries {
+		templates = append(templates, name)
+	}
+	slices.Sort(templates)
+
+	writeHeader(b, "postgres_queries_total", "counter", "Queries run, by template.")
+	for _, name := range templates {
+		fmt.Fprintf(b, "postgres_queries_total{template=%s} %d\n", labelValue(name), m.queries[name].count)
+	}
+
+	writeHeader(b, "postgres_query_errors_total", "counter", "Queries that failed, by template and SQLSTATE.")
+	for _, name := range templates {
+		q := m.queries[name]
+		codes := make([]string, 0, len(q.errors))
+		for code := range q.errors {
+			codes = append(codes, code)
+		}
+		slices.Sort(codes)
+
+		for _, code := range codes {
+			fmt.Fprintf(b, "postgres_query_errors_total{template=%s,sqlstate=%s} %d\n", labelValue(name), labelValue(code), q.errors[code])
+		}
+	}
+
+	writeHeader(b, "postgres_query_duration_seconds", "histogram", "Query latency, by template.")
+	for _, name := range templates {
+		q := m.queries[name]
+		label := labelValue(name)
+		for i, bound := range m.buckets {
+			fmt.Fprintf(b, "postgres_query_duration_seconds_bucket{template=%s,le=\"%s\"} %d\n", label, formatFloat(bound), q.buckets[i])
+		}
+		fmt.Fprintf(b, "postgres_query_duration_seconds_bucket{template=%s,le=\"+Inf\"} %d\n", label, q.count)
+		fmt.Fprintf(b, "postgres_query_duration_seconds_sum{template=%s} %s\n", label, formatFloat(q.sum))
+		fmt.Fprintf(b, "postgres_query_duration_seconds_count{template=%s} %d\n", label, q.count)
+	}
+
+	return b.Flush()
+}
+
+func writeHeader(w io.Writer, name string, kind string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func writeMetric[T int32 | int64 | float64](w io.Writer, name string, kind string, help string, value T) {
+	writeHeader(w, name, kind, help)
+	fmt.Fprintf(w, "%s %s\n", name, formatFloat(float64(value)))
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func labelValue(v string) string {
+	return `"` + labelReplacer.Replace(v) + `"`
+}
+
+// sqlState returns the SQLSTATE of the error, or an empty string for errors
+// that did not come from the server, such as a cancelled context.
+func sqlState(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+
+	return ""
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func traceQueryForTest(m *Metrics, ctx context.Context, now *time.Time, duration time.Duration, err error) {
+	ctx = m.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{})
+	*now = now.Add(duration)
+	m.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{Err: err})
+}
+
+func TestMetricsQueries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	m := NewMetrics([]float64{0.1, 0.01})
+	m.now = func() time.Time { return now }
+
+	named := withTemplateName(context.Background(), MustParseNamed(`users."list"`, "SELECT 1"))
+	traceQueryForTest(m, named, &now, 5*time.Millisecond, nil)
+	traceQueryForTest(m, named, &now, 50*time.Millisecond, &pgconn.PgError{Code: "23505"})
+	traceQueryForTest(m, named, &now, time.Second, &pgconn.PgError{Code: "23505"})
+	traceQueryForTest(m, context.Background(), &now, time.Millisecond, errors.New("canceled"))
+
+	// Queries without a start are ignored.
+	m.TraceQueryEnd(context.Background(), nil, pgx.TraceQueryEndData{})
+
+	var b strings.Builder
+	require.NoError(t, m.Write(&b))
+	assert.Equal(t, `# HELP postgres_queries_total Queries run, by template.
+# TYPE postgres_queries_total counter
+postgres_queries_total{template=""} 1
+postgres_queries_total{template="users.\"list\""} 3
+# HELP postgres_query_errors_total Queries that failed, by template and SQLSTATE.
+# TYPE postgres_query_errors_total counter
+postgres_query_errors_total{template="",sqlstate=""} 1
+postgres_query_errors_total{template="users.\"list\"",sqlstate="23505"} 2
+# HELP postgres_query_duration_seconds Query latency, by template.
+# TYPE postgres_query_duration_seconds histogram
+postgres_query_duration_seconds_bucket{template="",le="0.01"} 1
+postgres_query_duration_seconds_bucket{template="",le="0.1"} 1
+postgres_query_duration_seconds_bucket{template="",le="+Inf"} 1
+postgres_query_duration_seconds_sum{template=""} 0.001
+postgres_query_duration_seconds_count{template=""} 1
+postgres_query_duration_seconds_bucket{template="users.\"list\"",le="0.01"} 1
+postgres_query_duration_seconds_bucket{template="users.\"list\"",le="0.1"} 2
+postgres_query_duration_seconds_bucket{template="users.\"list\"",le="+Inf"} 3
+postgres_query_duration_seconds_sum{template="users.\"list\""} 1.055
+postgres_query_duration_seconds_count{template="users.\"list\""} 3
+`, b.String())
+}
+
+func TestMetricsPool(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	m := NewMetrics(DefaultLatencyBuckets)
+	config := dbPool.Config()
+	config.ConnConfig.Tracer = m
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	require.NoError(t, err)
+	defer pool.Close()
+	m.SetPool(pool)
+
+	ctx := context.Background()
+	_, err = ReadManyT[valueRow](ctx, pool, MustParseNamed("values.list", "SELECT * FROM values"), nil)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "postgres_pool_acquire_total 1\n")
+	assert.Contains(t, body, `postgres_queries_total{template="values.list"} 1`)
+	assert.Contains(t, body, `postgres_query_duration_seconds_count{template="values.list"} 1`)
+}
@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeremybower/go-common/nilable"
+	"github.com/jeremybower/go-common/optional"
+)
+
+// RegisterNullable allows nilable.Value and optional.Value to be used as
+// query arguments. They cannot implement driver.Valuer because of their Value
+// field, so they are encoded as the pointer returned by OrNil, where nil is
+// NULL. It is intended to be assigned to pgxpool.Config.AfterConnect.
+func RegisterNullable(ctx context.Context, conn *pgx.Conn) error {
+	registerNullable(conn.TypeMap())
+	return nil
+}
+
+func registerNullable(m *pgtype.Map) {
+	m.TryWrapEncodePlanFuncs = append(
+		[]pgtype.TryWrapEncodePlanFunc{tryWrapNullableEncodePlan},
+		m.TryWrapEncodePlanFuncs...,
+	)
+}
+
+// nullablePkgPaths are the packages whose Value types are encoded by OrNil.
+var nullablePkgPaths = map[string]bool{
+	reflect.TypeFor[nilable.Value[int]]().PkgPath():  true,
+	reflect.TypeFor[optional.Value[int]]().PkgPath(): true,
+}
+
+func tryWrapNullableEncodePlan(value any) (pgtype.WrappedEncodePlanNextSetter, any, bool) {
+	typ := reflect.TypeOf(value)
+	if typ == nil || !nullablePkgPaths[typ.PkgPath()] || !strings.HasPrefix(typ.Name(), "Value[") {
+		return nil, nil, false
+	}
+
+	method, ok := typ.MethodByName("OrNil")
+	if !ok {
+		return nil, nil, false
+	}
+
+	// The plan is cached for the type, so the method is only looked up once.
+	plan := &nullableEncodePlan{method: method.Index}
+	return plan, plan.orNil(value), true
+}
+
+type nullableEncodePlan struct {
+	next   pgtype.EncodePlan
+	method int
+}
+
+func (plan *nullableEncodePlan) SetNext(next pgtype.EncodePlan) {
+	plan.next = next
+}
+
+func (plan *nullableEncodePlan) Encode(value any, buf []byte) ([]byte, error) {
+	return plan.next.Encode(plan.orNil(value), buf)
+}
+
+// orNil calls the value's OrNil method, returning the typed pointer.
+func (plan *nullableEncodePlan) orNil(value any) any {
+	return reflect.ValueOf(value).Method(plan.method).Call(nil)[0].Interface()
+}
+
+// NullableArray is a nilable.Slice that pgx scans and encodes as an array,
+// where NULL is a valid nil slice and an invalid slice is encoded as NULL.
+// Multidimensional arrays are flattened. It is the supported way to scan an
+// array column, since nilable.Slice does not implement sql.Scanner. Convert
+// it with nilable.Slice[T].
+type NullableArray[T any] nilable.Slice[T]
+
+// Dimensions, Index and IndexType implement pgtype.ArrayGetter.
+func (a NullableArray[T]) Dimensions() []pgtype.ArrayDimension {
+	if nilable.Slice[T](a).OrNil() == nil {
+		return nil
+	}
+
+	return []pgtype.ArrayDimension{{Length: int32(len(a.Slice)), LowerBound: 1}}
+}
+
+func (a NullableArray[T]) Index(i int) any {
+	return a.Slice[i]
+}
+
+func (a NullableArray[T]) IndexType() any {
+	var el T
+	return el
+}
+
+// SetDimensions, ScanIndex and ScanIndexType implement pgtype.ArraySetter.
+func (a *NullableArray[T]) SetDimensions(dimensions []pgtype.ArrayDimension) error {
+	if dimensions == nil {
+		*a = NullableArray[T](nilable.NilSlice[T]())
+		return nil
+	}
+
+	count := 0
+	if len(dimensions) > 0 {
+		count = 1
+		for _, d := range dimensions {
+			count *= int(d.Length)
+		}
+	}
+
+	*a = NullableArray[T](nilable.NewSlice(make([]T, count)))
+	return nil
+}
+
+func (a *NullableArray[T]) ScanIndex(i int) any {
+	return &a.Slice[i]
+}
+
+func (a *NullableArray[T]) ScanIndexType() any {
+	return new(T)
+}
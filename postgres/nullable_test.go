@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeremybower/go-common/nilable"
+	"github.com/jeremybower/go-common/optional"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNullableEncode(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	registerNullable(m)
+
+	str := "hello"
+	tests := []struct {
+		name     string
+		value    any
+		expected []byte
+	}{
+		{"nilable value", nilable.NewValue(&str), []byte("hello")},
+		{"nilable nil", nilable.NilValue[string](), nil},
+		{"nilable invalid", nilable.InvalidValue[string](), nil},
+		{"optional value", optional.NewValue("hello"), []byte("hello")},
+		{"optional invalid", optional.InvalidValue[string](), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf, err := m.Encode(pgtype.TextOID, pgtype.TextFormatCode, tt.value, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf)
+		})
+	}
+}
+
+func TestNullableEncodeNotRegistered(t *testing.T) {
+	t.Parallel()
+
+	str := "hello"
+	_, err := pgtype.NewMap().Encode(pgtype.TextOID, pgtype.TextFormatCode, nilable.NewValue(&str), nil)
+	assert.Error(t, err)
+}
+
+type otherNullable struct{ s string }
+
+func (o otherNullable) OrNil() *string {
+	return &o.s
+}
+
+func TestNullableEncodeOnlyValues(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+	registerNullable(m)
+
+	// Pointers to the values are dereferenced by pgx.
+	v := optional.NewValue("hello")
+	buf, err := m.Encode(pgtype.TextOID, pgtype.TextFormatCode, &v, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello"), buf)
+
+	// Other types with an OrNil method are not encoded.
+	_, err = m.Encode(pgtype.TextOID, pgtype.TextFormatCode, otherNullable{s: "hello"}, nil)
+	assert.Error(t, err)
+}
+
+func TestNullableScan(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+
+	var name nilable.Value[string]
+	require.NoError(t, m.Scan(pgtype.TextOID, pgtype.TextFormatCode, []byte("hello"), &name))
+	assert.Equal(t, "hello", *name.Value)
+
+	require.NoError(t, m.Scan(pgtype.TextOID, pgtype.TextFormatCode, nil, &name))
+	assert.Equal(t, nilable.NilValue[string](), name)
+
+	var count optional.Value[int32]
+	require.NoError(t, m.Scan(pgtype.Int4OID, pgtype.TextFormatCode, []byte("42"), &count))
+	assert.Equal(t, optional.NewValue[int32](42), count)
+
+	err := m.Scan(pgtype.Int4OID, pgtype.TextFormatCode, nil, &count)
+	assert.ErrorIs(t, err, optional.ErrUnexpectedNull)
+}
+
+func TestNullableArrayRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+
+	for _, format := range []int16{pgtype.TextFormatCode, pgtype.BinaryFormatCode} {
+		buf, err := m.Encode(pgtype.Int4ArrayOID, format, NullableArray[int32](nilable.NewSlice([]int32{1, 2, 3})), nil)
+		require.NoError(t, err)
+
+		var a NullableArray[int32]
+		require.NoError(t, m.Scan(pgtype.Int4ArrayOID, format, buf, &a))
+		assert.Equal(t, nilable.NewSlice([]int32{1, 2, 3}), nilable.Slice[int32](a))
+
+		buf, err = m.Encode(pgtype.Int4ArrayOID, format, NullableArray[int32](nilable.NilSlice[int32]()), nil)
+		require.NoError(t, err)
+		assert.Nil(t, buf)
+
+		buf, err = m.Encode(pgtype.Int4ArrayOID, format, NullableArray[int32](nilable.InvalidSlice[int32]()), nil)
+		require.NoError(t, err)
+		assert.Nil(t, buf)
+
+		require.NoError(t, m.Scan(pgtype.Int4ArrayOID, format, nil, &a))
+		assert.Equal(t, nilable.NilSlice[int32](), nilable.Slice[int32](a))
+	}
+}
+
+func TestNullableSliceEncode(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+
+	buf, err := m.Encode(pgtype.Int4ArrayOID, pgtype.TextFormatCode, nilable.NewSlice([]int32{1, 2, 3}), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "{1,2,3}", string(buf))
+
+	buf, err = m.Encode(pgtype.Int4ArrayOID, pgtype.TextFormatCode, nilable.NilSlice[int32](), nil)
+	require.NoError(t, err)
+	assert.Nil(t, buf)
+}
+
+func TestNullableMapRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	m := pgtype.NewMap()
+
+	buf, err := m.Encode(pgtype.JSONBOID, pgtype.TextFormatCode, nilable.NewMap(map[string]any{"a": "b"}), nil)
+	require.NoError(t, err)
+
+	var data nilable.Map[string, any]
+	require.NoError(t, m.Scan(pgtype.JSONBOID, pgtype.TextFormatCode, buf, &data))
+	assert.Equal(t, nilable.NewMap(map[string]any{"a": "b"}), data)
+
+	require.NoError(t, m.Scan(pgtype.JSONBOID, pgtype.TextFormatCode, nil, &data))
+	assert.Equal(t, nilable.NilMap[string, any](), data)
+}
+
+func TestReadOneNullable(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	conn, err := dbPool.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+
+	require.NoError(t, RegisterNullable(ctx, conn.Conn()))
+
+	type row struct {
+		Name  nilable.Value[string]    `db:"name"`
+		Note  nilable.Value[string]    `db:"note"`
+		Count optional.Value[int64]    `db:"count"`
+		IDs   NullableArray[int64]     `db:"ids"`
+		Data  nilable.Map[string, any] `db:"data"`
+	}
+
+	str := "hello"
+	r, err := ReadOne[row](ctx, conn,
+		"SELECT $1::text AS name, $2::text AS note, $3::int8 AS count, $4::int8[] AS ids, $5::jsonb AS data",
+		nilable.NewValue(&str),
+		nilable.NilValue[string](),
+		optional.NewValue[int64](3),
+		nilable.NewSlice([]int64{1, 2}),
+		nilable.NilMap[string, any](),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, nilable.NewValue(&str), r.Name)
+	assert.Equal(t, nilable.NilValue[string](), r.Note)
+	assert.Equal(t, optional.NewValue[int64](3), r.Count)
+	assert.Equal(t, nilable.NewSlice([]int64{1, 2}), nilable.Slice[int64](r.IDs))
+	assert.Equal(t, nilable.NilMap[string, any](), r.Data)
+}
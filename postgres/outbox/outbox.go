@@ -0,0 +1,63 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jeremybower/go-common/postgres"
+)
+
+var ErrOutbox = errors.New("postgres outbox")
+var ErrEncodePayload = fmt.Errorf("%w: failed to encode payload", ErrOutbox)
+
+// Migration creates the outbox table. Unpublished events are indexed so that
+// the relay does not scan events that have already been published.
+const Migration = `
+CREATE TABLE IF NOT EXISTS outbox (
+	id BIGSERIAL PRIMARY KEY,
+	topic TEXT NOT NULL,
+	payload JSONB NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	published_at TIMESTAMPTZ,
+	attempts INT NOT NULL DEFAULT 0,
+	last_error TEXT
+);
+
+CREATE INDEX IF NOT EXISTS outbox_unpublished_idx ON outbox (id) WHERE published_at IS NULL;
+`
+
+type Event struct {
+	ID        int64           `db:"id"`
+	Topic     string          `db:"topic"`
+	Payload   json.RawMessage `db:"payload"`
+	CreatedAt time.Time       `db:"created_at"`
+	Attempts  int32           `db:"attempts"`
+}
+
+// Write adds an event to the outbox. It must be called with the transaction
+// that makes the change the event describes, such as the one passed by
+// postgres.WithTx, so that the event is published if and only if the change
+// is committed.
+func Write(ctx context.Context, tx pgx.Tx, topic string, payload any) (int64, error) {
+	// Encode the payload.
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrEncodePayload, err)
+	}
+
+	// Insert the event.
+	id, err := postgres.ReadOne[int64](ctx, tx,
+		"INSERT INTO outbox (topic, payload) VALUES ($1, $2) RETURNING id",
+		topic, b,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	// Success.
+	return *id, nil
+}
@@ -0,0 +1,64 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common/internal/pgtest"
+	"github.com/jeremybower/go-common/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func databasePoolForTesting(t *testing.T) *pgxpool.Pool {
+	return pgtest.Pool(t, Migration)
+}
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	// The event is written with the transaction.
+	ctx := context.Background()
+	var id int64
+	err := postgres.WithTx(ctx, dbPool, func(tx pgx.Tx) error {
+		var err error
+		id, err = Write(ctx, tx, "user.created", map[string]string{"name": "alice"})
+		return err
+	})
+	require.NoError(t, err)
+
+	event, err := postgres.ReadOne[Event](ctx, dbPool,
+		"SELECT id, topic, payload, created_at, attempts FROM outbox WHERE id = $1", id)
+	require.NoError(t, err)
+	assert.Equal(t, "user.created", event.Topic)
+	assert.JSONEq(t, `{"name": "alice"}`, string(event.Payload))
+	assert.Equal(t, int32(0), event.Attempts)
+
+	// The event is discarded with the transaction.
+	errRollback := errors.New("rollback")
+	err = postgres.WithTx(ctx, dbPool, func(tx pgx.Tx) error {
+		if _, err := Write(ctx, tx, "user.created", map[string]string{"name": "bob"}); err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	c, err := postgres.Count(ctx, dbPool, "SELECT COUNT(*) FROM outbox")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+}
+
+func TestWriteEncodeError(t *testing.T) {
+	t.Parallel()
+
+	_, err := Write(context.Background(), nil, "invalid", make(chan int))
+	assert.ErrorIs(t, err, ErrEncodePayload)
+}
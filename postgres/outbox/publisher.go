@@ -0,0 +1,52 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// Publisher delivers events to a broker. Events may be delivered more than
+// once, such as when the relay fails to mark an event as published, so
+// consumers should deduplicate by Event.ID.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// MemoryPublisher records published events in memory for tests.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+	err    error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events returns the published events in the order they were published.
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Event(nil), p.events...)
+}
+
+// SetErr makes Publish fail with err until it is set to nil.
+func (p *MemoryPublisher) SetErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.err = err
+}
@@ -0,0 +1,26 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryPublisher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	p := NewMemoryPublisher()
+	assert.NoError(t, p.Publish(ctx, Event{ID: 1}))
+
+	errFailed := errors.New("failed")
+	p.SetErr(errFailed)
+	assert.ErrorIs(t, p.Publish(ctx, Event{ID: 2}), errFailed)
+
+	p.SetErr(nil)
+	assert.NoError(t, p.Publish(ctx, Event{ID: 3}))
+
+	assert.Equal(t, []Event{{ID: 1}, {ID: 3}}, p.Events())
+}
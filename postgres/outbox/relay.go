@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	common "github.com/jeremybower/go-common"
+	"github.com/jeremybower/go-common/backoff"
+	"github.com/jeremybower/go-common/postgres"
+)
+
+var ErrPublish = fmt.Errorf("%w: failed to publish", ErrOutbox)
+
+// Relay publishes the events in the outbox in id order.
+type Relay struct {
+	querier   postgres.Querier
+	publisher Publisher
+	batchSize int
+	interval  time.Duration
+	backoff   *backoff.Backoff
+}
+
+// NewRelay creates a relay that polls for events every interval and waits
+// according to backoff after a failure.
+func NewRelay(
+	querier postgres.Querier,
+	publisher Publisher,
+	batchSize int,
+	interval time.Duration,
+	backoff *backoff.Backoff,
+) *Relay {
+	return &Relay{
+		querier:   querier,
+		publisher: publisher,
+		batchSize: batchSize,
+		interval:  interval,
+		backoff:   backoff,
+	}
+}
+
+// RelayBatch publishes up to batchSize unpublished events and marks them as
+// published, returning how many were published. The events are locked while
+// they are published, so concurrent relays wait for each other rather than
+// publishing an event twice. Publishing stops at the first failure, which is
+// recorded against the event and returned wrapped in ErrPublish.
+//
+// Events are published in id order, which is the order their ids were
+// allocated rather than the order their transactions committed. An event
+// whose transaction commits after a later event has been published is
+// published after it, so consumers that need a strict order should order by
+// their own sequence within the payload.
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	var published []int64
+	var publishErr error
+	err := postgres.WithTx(ctx, r.querier, func(tx pgx.Tx) error {
+		// Lock the next unpublished events.
+		events, err := postgres.ReadMany[Event](ctx, tx, `
+			SELECT id, topic, payload, created_at, attempts
+			FROM outbox
+			WHERE published_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE`,
+			r.batchSize,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Publish the events in order.
+		for _, event := range events {
+			if pubErr := r.publisher.Publish(ctx, *event); pubErr != nil {
+				publishErr = fmt.Errorf("%w: event %d: %w", ErrPublish, event.ID, pubErr)
+				if _, err := postgres.Exec(ctx, tx,
+					"UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1",
+					event.ID, pubErr.Error(),
+				); err != nil {
+					return err
+				}
+
+				break
+			}
+
+			published = append(published, event.ID)
+		}
+
+		// Mark the published events.
+		if len(published) > 0 {
+			if _, err := postgres.Exec(ctx, tx,
+				"UPDATE outbox SET published_at = now() WHERE id = ANY($1)",
+				published,
+			); err != nil {
+				return err
+			}
+		}
+
+		// Success.
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Success.
+	return len(published), publishErr
+}
+
+// Run relays events until the context is cancelled. Full batches are
+// followed immediately by the next batch. Failures are logged with the
+// context's logger, when set, and retried after the backoff delay.
+func (r *Relay) Run(ctx context.Context) error {
+	for {
+		n, err := r.RelayBatch(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var wait <-chan time.Time
+		switch {
+		case err != nil:
+			if logger, loggerErr := common.Logger(ctx); loggerErr == nil {
+				logger.ErrorContext(ctx, "failed to relay outbox events", "error", err)
+			}
+
+			wait = r.backoff.Wait()
+		case n == r.batchSize:
+			r.backoff.Reset()
+			continue
+		default:
+			r.backoff.Reset()
+			wait = time.After(r.interval)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jeremybower/go-common/backoff"
+	"github.com/jeremybower/go-common/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayBatch(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	err := postgres.WithTx(ctx, dbPool, func(tx pgx.Tx) error {
+		for _, topic := range []string{"a", "b", "c"} {
+			if _, err := Write(ctx, tx, topic, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	publisher := NewMemoryPublisher()
+	relay := NewRelay(dbPool, publisher, 2, time.Second, backoff.New(1, 60, 300, true))
+
+	// A failure is recorded and nothing is published.
+	errFailed := errors.New("failed")
+	publisher.SetErr(errFailed)
+	n, err := relay.RelayBatch(ctx)
+	assert.ErrorIs(t, err, ErrPublish)
+	assert.ErrorIs(t, err, errFailed)
+	assert.Equal(t, 0, n)
+
+	attempts, err := postgres.ReadOne[int32](ctx, dbPool, "SELECT attempts FROM outbox WHERE topic = 'a'")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), *attempts)
+
+	// The events are published in order, one batch at a time.
+	publisher.SetErr(nil)
+	n, err = relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	n, err = relay.RelayBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	var topics []string
+	for _, event := range publisher.Events() {
+		topics = append(topics, event.Topic)
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, topics)
+}
+
+func TestRelayRun(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	publisher := NewMemoryPublisher()
+	relay := NewRelay(dbPool, publisher, 10, 10*time.Millisecond, backoff.New(1, 60, 300, true))
+
+	done := make(chan error)
+	go func() { done <- relay.Run(ctx) }()
+
+	err := postgres.WithTx(ctx, dbPool, func(tx pgx.Tx) error {
+		_, err := Write(ctx, tx, "a", nil)
+		return err
+	})
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		return len(publisher.Events()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common/backoff"
+	"github.com/jeremybower/go-common/env"
+	"github.com/jeremybower/go-common/optional"
+)
+
+var ErrPool = errors.New("postgres pool")
+var ErrPoolNotReady = fmt.Errorf("%w: database not ready", ErrPool)
+
+const (
+	DefaultMaxConns              int32 = 10
+	DefaultMinConns              int32 = 0
+	DefaultMaxConnLifetimeSecs   int32 = 60 * 60
+	DefaultMaxConnIdleTimeSecs   int32 = 30 * 60
+	DefaultHealthCheckPeriodSecs int32 = 60
+	DefaultConnectTimeoutSecs    int32 = 5
+	DefaultReadyTimeoutSecs      int32 = 30
+)
+
+// NewPoolFromEnv creates a pool from environment variables named with the
+// prefix, then pings the database until it is ready or the ready timeout
+// passes. Like the env package, it panics when a variable is invalid.
+//
+//	DATABASE_URL                     required
+//	DATABASE_MAX_CONNS               DefaultMaxConns
+//	DATABASE_MIN_CONNS               DefaultMinConns
+//	DATABASE_MAX_CONN_LIFETIME_SECS  DefaultMaxConnLifetimeSecs
+//	DATABASE_MAX_CONN_IDLE_TIME_SECS DefaultMaxConnIdleTimeSecs
+//	DATABASE_HEALTH_CHECK_SECS       DefaultHealthCheckPeriodSecs
+//	DATABASE_CONNECT_TIMEOUT_SECS    DefaultConnectTimeoutSecs
+//	DATABASE_READY_TIMEOUT_SECS      DefaultReadyTimeoutSecs
+//
+// The pool and connect timeout parameters of the URL, such as
+// pool_max_conns, are used instead of the defaults, but the variables take
+// precedence over them.
+//
+// The tracer, when not nil, traces every query. Nullable types are
+// registered on every connection, followed by afterConnect, such as
+// Types.AfterConnect and Statements.AfterConnect.
+func NewPoolFromEnv(
+	ctx context.Context,
+	prefix string,
+	tracer pgx.QueryTracer,
+	afterConnect ...func(ctx context.Context, conn *pgx.Conn) error,
+) (*pgxpool.Pool, error) {
+	// Read the configuration.
+	config, err := poolConfigFromEnv(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	readyTimeout := secs(env.OptionalInt32(prefix+"DATABASE_READY_TIMEOUT_SECS", env.NonNegative).Or(DefaultReadyTimeoutSecs))
+
+	config.ConnConfig.Tracer = tracer
+	config.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		if err := RegisterNullable(ctx, conn); err != nil {
+			return err
+		}
+
+		for _, fn := range afterConnect {
+			if err := fn(ctx, conn); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	// Create the pool.
+	pool, err := pgxpool.NewWithConfig(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wait for the database.
+	if err := waitForPool(ctx, pool, readyTimeout); err != nil {
+		pool.Close()
+		return nil, err
+	}
+
+	// Success.
+	return pool, nil
+}
+
+func poolConfigFromEnv(prefix string) (*pgxpool.Config, error) {
+	u := env.RequiredURL(prefix + "DATABASE_URL")
+	config, err := pgxpool.ParseConfig(u.String())
+	if err != nil {
+		return nil, err
+	}
+
+	// Parameters in the URL have already been applied by ParseConfig, so
+	// they are only overridden by variables that are set.
+	query := u.Query()
+	setting := func(param string, v optional.Value[int32], def int32, fn func(int32)) {
+		switch {
+		case v.Valid:
+			fn(v.Value)
+		case !query.Has(param):
+			fn(def)
+		}
+	}
+
+	setting("pool_max_conns", env.OptionalInt32(prefix+"DATABASE_MAX_CONNS", env.Positive), DefaultMaxConns,
+		func(v int32) { config.MaxConns = v })
+	setting("pool_min_conns", env.OptionalInt32(prefix+"DATABASE_MIN_CONNS", env.NonNegative), DefaultMinConns,
+		func(v int32) { config.MinConns = v })
+	setting("pool_max_conn_lifetime", env.OptionalInt32(prefix+"DATABASE_MAX_CONN_LIFETIME_SECS", env.Positive), DefaultMaxConnLifetimeSecs,
+		func(v int32) { config.MaxConnLifetime = secs(v) })
+	setting("pool_max_conn_idle_time", env.OptionalInt32(prefix+"DATABASE_MAX_CONN_IDLE_TIME_SECS", env.Positive), DefaultMaxConnIdleTimeSecs,
+		func(v int32) { config.MaxConnIdleTime = secs(v) })
+	setting("pool_health_check_period", env.OptionalInt32(prefix+"DATABASE_HEALTH_CHECK_SECS", env.Positive), DefaultHealthCheckPeriodSecs,
+		func(v int32) { config.HealthCheckPeriod = secs(v) })
+	setting("connect_timeout", env.OptionalInt32(prefix+"DATABASE_CONNECT_TIMEOUT_SECS", env.Positive), DefaultConnectTimeoutSecs,
+		func(v int32) { config.ConnConfig.ConnectTimeout = secs(v) })
+
+	if config.MinConns > config.MaxConns {
+		return nil, fmt.Errorf("%w: min conns %d exceeds max conns %d", ErrPool, config.MinConns, config.MaxConns)
+	}
+
+	// Success.
+	return config, nil
+}
+
+func waitForPool(ctx context.Context, pool *pgxpool.Pool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	b := backoff.New(1, 5, 60, true)
+	for {
+		err := pool.Ping(ctx)
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrPoolNotReady, err)
+		case <-b.Wait():
+		}
+	}
+}
+
+func secs(s int32) time.Duration {
+	return time.Duration(s) * time.Second
+}
@@ -0,0 +1,184 @@
+package qb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jeremybower/go-common"
+	"github.com/jeremybower/go-common/pagination"
+	"github.com/jeremybower/go-common/postgres"
+)
+
+type Builder interface {
+	Build() (string, []any, error)
+}
+
+func Count(
+	ctx context.Context,
+	querier postgres.Querier,
+	q *SelectBuilder,
+) (int64, error) {
+	// Build the SQL.
+	sql, args, err := q.BuildCount()
+	if err != nil {
+		return 0, err
+	}
+
+	// Success.
+	return postgres.Count(ctx, querier, sql, args...)
+}
+
+func Exec(
+	ctx context.Context,
+	querier postgres.Querier,
+	q Builder,
+) (int64, error) {
+	// Build the SQL.
+	sql, args, err := q.Build()
+	if err != nil {
+		return 0, err
+	}
+
+	// Success.
+	return postgres.Exec(ctx, querier, sql, args...)
+}
+
+func ReadOne[T any](
+	ctx context.Context,
+	querier postgres.Querier,
+	q Builder,
+) (*T, error) {
+	// Build the SQL.
+	sql, args, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return postgres.ReadOne[T](ctx, querier, sql, args...)
+}
+
+func ReadMany[T any](
+	ctx context.Context,
+	querier postgres.Querier,
+	q Builder,
+) ([]*T, error) {
+	// Build the SQL.
+	sql, args, err := q.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return postgres.ReadMany[T](ctx, querier, sql, args...)
+}
+
+// VersionColumn is the column that UpdateOneVersion checks and increments.
+const VersionColumn = "version"
+
+// UpdateOne runs the update and reads the row it returns, failing with
+// common.ErrNotFound when no row matched.
+func UpdateOne[T any](
+	ctx context.Context,
+	querier postgres.Querier,
+	q *UpdateBuilder,
+) (*T, error) {
+	return ReadOne[T](ctx, querier, q)
+}
+
+// UpdateOneVersion runs the update only when the row's VersionColumn equals
+// version, incrementing it. It fails with common.ErrStaleVersion when the row
+// exists but was changed since version was read, and with
+// common.ErrNotFound when it does not exist. The updated row is read from the
+// RETURNING clause, which defaults to every column.
+func UpdateOneVersion[T any](
+	ctx context.Context,
+	querier postgres.Querier,
+	q *UpdateBuilder,
+	version int64,
+) (*T, error) {
+	versioned := q.versioned(VersionColumn, version).
+		Set(VersionColumn, E(VersionColumn+" + 1"))
+
+	return updateOneChecked[T](ctx, querier, q, versioned)
+}
+
+// UpdateOneXmin is like UpdateOneVersion for tables without a version
+// column. It compares the row's xmin system column, which Postgres changes
+// whenever the row is updated. Read it with the row, such as
+// `SELECT xmin, * FROM values`.
+func UpdateOneXmin[T any](
+	ctx context.Context,
+	querier postgres.Querier,
+	q *UpdateBuilder,
+	xmin uint32,
+) (*T, error) {
+	return updateOneChecked[T](ctx, querier, q, q.versioned("xmin", xmin))
+}
+
+func updateOneChecked[T any](
+	ctx context.Context,
+	querier postgres.Querier,
+	q *UpdateBuilder,
+	versioned *UpdateBuilder,
+) (*T, error) {
+	// Update the row.
+	item, err := ReadOne[T](ctx, querier, versioned)
+	if !errors.Is(err, common.ErrNotFound) {
+		return item, err
+	}
+
+	// Distinguish a changed row from a missing row.
+	c, err := Count(ctx, querier, q.matching())
+	if err != nil {
+		return nil, err
+	}
+
+	if c > 0 {
+		return nil, common.ErrStaleVersion
+	}
+
+	return nil, common.ErrNotFound
+}
+
+func List[T any](
+	ctx context.Context,
+	querier postgres.Querier,
+	q *SelectBuilder,
+	pageIndex int64,
+	pageSize int64,
+	minimumPageSize int64,
+	defaultPageSize int64,
+	maximumPageSize int64,
+) (*pagination.Result[*T], error) {
+	// Count the total items.
+	totalItems, err := Count(ctx, querier, q)
+	if err != nil {
+		return nil, err
+	}
+
+	// Normalize pagination.
+	norm := pagination.Normalize(totalItems, pageIndex, pageSize, minimumPageSize, defaultPageSize, maximumPageSize)
+
+	// Build the SQL.
+	sql, args, err := q.BuildPage(norm.FirstItemIndex, norm.PageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the items.
+	items, err := postgres.ReadMany[T](ctx, querier, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Success.
+	return &pagination.Result[*T]{
+		PageIndex:      norm.PageIndex,
+		PageSize:       norm.PageSize,
+		FirstItemIndex: norm.FirstItemIndex,
+		TotalItems:     norm.TotalItems,
+		TotalPages:     norm.TotalPages,
+		Items:          items,
+	}, nil
+}
@@ -0,0 +1,160 @@
+package qb
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common"
+	"github.com/jeremybower/go-common/internal/pgtest"
+	"github.com/jeremybower/go-common/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type valueRow struct {
+	ID    int64  `db:"id"`
+	Name  string `db:"name"`
+	Value string `db:"value"`
+}
+
+func databasePoolForTesting(t *testing.T) *pgxpool.Pool {
+	return pgtest.Pool(t, "CREATE TABLE values (id BIGSERIAL PRIMARY KEY, name TEXT NOT NULL, value TEXT NOT NULL);")
+}
+
+func TestCRUD(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	row, err := ReadOne[valueRow](ctx, dbPool, Insert("values").Columns("name", "value").Values("name", "value").Returning("*"))
+	require.NoError(t, err)
+	assert.Equal(t, "name", row.Name)
+
+	c, err := Exec(ctx, dbPool, Update("values").Set("value", "updated").Where("id = ?", row.ID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	rows, err := ReadMany[valueRow](ctx, dbPool, Select("*").From("values").Where("id = ?", row.ID))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "updated", rows[0].Value)
+
+	c, err = Count(ctx, dbPool, Select("*").From("values"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	c, err = Exec(ctx, dbPool, Delete("values").Where("id = ?", row.ID))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+}
+
+type versionedRow struct {
+	ID      int64  `db:"id"`
+	Value   string `db:"value"`
+	Version int64  `db:"version"`
+}
+
+func TestUpdateOneVersion(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := dbPool.Exec(ctx, "CREATE TABLE versioned (id BIGSERIAL PRIMARY KEY, value TEXT NOT NULL, version BIGINT NOT NULL DEFAULT 1)")
+	require.NoError(t, err)
+
+	row, err := ReadOne[versionedRow](ctx, dbPool, Insert("versioned").Columns("value").Values("a").Returning("*"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), row.Version)
+
+	// The expected version is updated and incremented.
+	updated, err := UpdateOneVersion[versionedRow](ctx, dbPool,
+		Update("versioned").Set("value", "b").Where("id = ?", row.ID).Returning("*"), row.Version)
+	require.NoError(t, err)
+	assert.Equal(t, "b", updated.Value)
+	assert.Equal(t, int64(2), updated.Version)
+
+	// A lost update is stale.
+	_, err = UpdateOneVersion[versionedRow](ctx, dbPool,
+		Update("versioned").Set("value", "c").Where("id = ?", row.ID).Returning("*"), row.Version)
+	assert.ErrorIs(t, err, common.ErrStaleVersion)
+
+	// A missing row is not found.
+	_, err = UpdateOneVersion[versionedRow](ctx, dbPool,
+		Update("versioned").Set("value", "c").Where("id = ?", row.ID+1).Returning("*"), row.Version)
+	assert.ErrorIs(t, err, common.ErrNotFound)
+
+	// Without a RETURNING clause, the row is still returned.
+	updated, err = UpdateOneVersion[versionedRow](ctx, dbPool,
+		Update("versioned").Set("value", "d").Where("id = ?", row.ID), updated.Version)
+	require.NoError(t, err)
+	assert.Equal(t, "d", updated.Value)
+	assert.Equal(t, int64(3), updated.Version)
+
+	_, err = UpdateOneVersion[versionedRow](ctx, dbPool,
+		Update("versioned").Set("value", "e").Where("id = ?", row.ID), row.Version)
+	assert.ErrorIs(t, err, common.ErrStaleVersion)
+}
+
+func TestUpdateOneXmin(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	type xminRow struct {
+		Xmin uint32 `db:"xmin"`
+		valueRow
+	}
+
+	ctx := context.Background()
+	row, err := ReadOne[xminRow](ctx, dbPool, Insert("values").Columns("name", "value").Values("name", "a").Returning("xmin", "*"))
+	require.NoError(t, err)
+
+	updated, err := UpdateOneXmin[xminRow](ctx, dbPool,
+		Update("values").Set("value", "b").Where("id = ?", row.ID).Returning("xmin", "*"), row.Xmin)
+	require.NoError(t, err)
+	assert.Equal(t, "b", updated.Value)
+	assert.NotEqual(t, row.Xmin, updated.Xmin)
+
+	_, err = UpdateOneXmin[xminRow](ctx, dbPool,
+		Update("values").Set("value", "c").Where("id = ?", row.ID).Returning("xmin", "*"), row.Xmin)
+	assert.ErrorIs(t, err, common.ErrStaleVersion)
+
+	_, err = UpdateOneXmin[xminRow](ctx, dbPool,
+		Update("values").Set("value", "c").Where("id = ?", row.ID+1).Returning("xmin", "*"), row.Xmin)
+	assert.ErrorIs(t, err, common.ErrNotFound)
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	q := Insert("values").Columns("name", "value")
+	for i := 0; i < 3; i++ {
+		q.Values("name"+strconv.Itoa(i), "value"+strconv.Itoa(i))
+	}
+	_, err := Exec(ctx, dbPool, q)
+	require.NoError(t, err)
+
+	paged, err := List[valueRow](ctx, dbPool, Select("*").From("values").OrderBy("id"), 1, 2, 1, 10, 100)
+	require.NoError(t, err)
+	assert.Equal(t, pagination.Result[*valueRow]{
+		PageIndex:      1,
+		PageSize:       2,
+		FirstItemIndex: 2,
+		TotalItems:     3,
+		TotalPages:     2,
+		Items: []*valueRow{
+			{ID: 3, Name: "name2", Value: "value2"},
+		},
+	}, *paged)
+}
@@ -0,0 +1,50 @@
+package qb
+
+type DeleteBuilder struct {
+	table     string
+	using     string
+	where     []Expr
+	returning []string
+}
+
+func Delete(table string) *DeleteBuilder {
+	return &DeleteBuilder{table: table}
+}
+
+func (q *DeleteBuilder) Using(table string) *DeleteBuilder {
+	q.using = table
+	return q
+}
+
+// Where adds a condition that is combined with any other conditions using
+// AND.
+func (q *DeleteBuilder) Where(sql string, args ...any) *DeleteBuilder {
+	return q.WhereExpr(E(sql, args...))
+}
+
+func (q *DeleteBuilder) WhereExpr(expr Expr) *DeleteBuilder {
+	q.where = append(q.where, expr)
+	return q
+}
+
+func (q *DeleteBuilder) Returning(columns ...string) *DeleteBuilder {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+func (q *DeleteBuilder) Build() (string, []any, error) {
+	b := &builder{}
+
+	if q.table == "" {
+		b.fail(ErrNoTable)
+	}
+
+	b.write("DELETE FROM ", q.table)
+	if q.using != "" {
+		b.write(" USING ", q.using)
+	}
+
+	b.where(q.where)
+	b.returning(q.returning)
+	return b.result()
+}
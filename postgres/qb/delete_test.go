@@ -0,0 +1,19 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteBuild(t *testing.T) {
+	t.Parallel()
+
+	sql, args, err := Delete("values v").Using("others o").Where("o.id = v.id").Where("o.name = ?", "a").Returning("v.id").Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "DELETE FROM values v USING others o WHERE (o.id = v.id) AND (o.name = $1) RETURNING v.id", sql)
+	assert.Equal(t, []any{"a"}, args)
+
+	_, _, err = Delete("").Build()
+	assert.ErrorIs(t, err, ErrNoTable)
+}
@@ -0,0 +1,82 @@
+package qb
+
+import (
+	"strings"
+)
+
+type InsertBuilder struct {
+	table      string
+	columns    []string
+	rows       [][]any
+	onConflict *Expr
+	returning  []string
+}
+
+func Insert(table string) *InsertBuilder {
+	return &InsertBuilder{table: table}
+}
+
+func (q *InsertBuilder) Columns(columns ...string) *InsertBuilder {
+	q.columns = append(q.columns, columns...)
+	return q
+}
+
+// Values adds a row of values. An Expr value is written as SQL instead of
+// being passed as an argument, such as E("now()").
+func (q *InsertBuilder) Values(values ...any) *InsertBuilder {
+	q.rows = append(q.rows, values)
+	return q
+}
+
+// OnConflict sets the conflict clause that follows ON CONFLICT, such as
+// "(id) DO NOTHING".
+func (q *InsertBuilder) OnConflict(sql string, args ...any) *InsertBuilder {
+	expr := E(sql, args...)
+	q.onConflict = &expr
+	return q
+}
+
+func (q *InsertBuilder) Returning(columns ...string) *InsertBuilder {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+func (q *InsertBuilder) Build() (string, []any, error) {
+	b := &builder{}
+
+	if q.table == "" {
+		b.fail(ErrNoTable)
+	}
+
+	if len(q.columns) == 0 || len(q.rows) == 0 {
+		b.fail(ErrNoColumns)
+	}
+
+	b.write("INSERT INTO ", q.table, " (", strings.Join(q.columns, ", "), ") VALUES ")
+	for i, row := range q.rows {
+		if len(row) != len(q.columns) {
+			b.fail(ErrColumnCount)
+		}
+
+		if i > 0 {
+			b.write(", ")
+		}
+
+		b.write("(")
+		for j, value := range row {
+			if j > 0 {
+				b.write(", ")
+			}
+			b.value(value)
+		}
+		b.write(")")
+	}
+
+	if q.onConflict != nil {
+		b.write(" ON CONFLICT ")
+		b.expr(*q.onConflict)
+	}
+
+	b.returning(q.returning)
+	return b.result()
+}
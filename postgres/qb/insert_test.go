@@ -0,0 +1,67 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertBuild(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        *InsertBuilder
+		expectedErr  error
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			name:         "simple",
+			query:        Insert("values").Columns("name", "value").Values("a", "b"),
+			expectedSQL:  "INSERT INTO values (name, value) VALUES ($1, $2)",
+			expectedArgs: []any{"a", "b"},
+		},
+		{
+			name: "complete",
+			query: Insert("values").
+				Columns("name", "value").
+				Values("a", "b").
+				Values("c", E("upper(?)", "d")).
+				OnConflict("(name) DO UPDATE SET value = EXCLUDED.value WHERE values.value <> ?", "x").
+				Returning("id", "name"),
+			expectedSQL: "INSERT INTO values (name, value) VALUES ($1, $2), ($3, upper($4)) " +
+				"ON CONFLICT (name) DO UPDATE SET value = EXCLUDED.value WHERE values.value <> $5 " +
+				"RETURNING id, name",
+			expectedArgs: []any{"a", "b", "c", "d", "x"},
+		},
+		{
+			name:        "no table",
+			query:       Insert("").Columns("name").Values("a"),
+			expectedErr: ErrNoTable,
+		},
+		{
+			name:        "no values",
+			query:       Insert("values").Columns("name"),
+			expectedErr: ErrNoColumns,
+		},
+		{
+			name:        "column count",
+			query:       Insert("values").Columns("name", "value").Values("a"),
+			expectedErr: ErrColumnCount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.query.Build()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedSQL, sql)
+				assert.Equal(t, tt.expectedArgs, args)
+			}
+		})
+	}
+}
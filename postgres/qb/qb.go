@@ -0,0 +1,155 @@
+package qb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrQueryBuilder = errors.New("postgres query builder")
+var ErrArgCount = fmt.Errorf("%w: placeholder and argument counts differ", ErrQueryBuilder)
+var ErrColumnCount = fmt.Errorf("%w: column and value counts differ", ErrQueryBuilder)
+var ErrNoColumns = fmt.Errorf("%w: no columns", ErrQueryBuilder)
+var ErrNoTable = fmt.Errorf("%w: no table", ErrQueryBuilder)
+
+// Expr is a fragment of SQL where each ? is a placeholder for the
+// corresponding argument. Use ?? for a literal question mark, such as the
+// jsonb key exists operator.
+type Expr struct {
+	SQL  string
+	Args []any
+}
+
+func E(sql string, args ...any) Expr {
+	return Expr{SQL: sql, Args: args}
+}
+
+func And(exprs ...Expr) Expr {
+	return combine("AND", "TRUE", exprs)
+}
+
+func Or(exprs ...Expr) Expr {
+	return combine("OR", "FALSE", exprs)
+}
+
+func Not(expr Expr) Expr {
+	return Expr{SQL: "NOT (" + expr.SQL + ")", Args: expr.Args}
+}
+
+func combine(op string, empty string, exprs []Expr) Expr {
+	if len(exprs) == 0 {
+		return Expr{SQL: empty}
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+
+	var args []any
+	parts := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		parts = append(parts, "("+expr.SQL+")")
+		args = append(args, expr.Args...)
+	}
+
+	return Expr{SQL: strings.Join(parts, " "+op+" "), Args: args}
+}
+
+type builder struct {
+	sql  strings.Builder
+	args []any
+	err  error
+}
+
+func (b *builder) write(s ...string) {
+	for _, str := range s {
+		b.sql.WriteString(str)
+	}
+}
+
+func (b *builder) arg(arg any) {
+	b.args = append(b.args, arg)
+	b.sql.WriteString("$" + strconv.Itoa(len(b.args)))
+}
+
+// expr writes the expression, replacing each ? outside of a quoted string
+// with the next numbered placeholder.
+func (b *builder) expr(expr Expr) {
+	next := 0
+	quote := rune(0)
+	runes := []rune(expr.SQL)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+			b.sql.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			b.sql.WriteRune(r)
+		case r == '?' && i+1 < len(runes) && runes[i+1] == '?':
+			b.sql.WriteRune('?')
+			i++
+		case r == '?':
+			if next >= len(expr.Args) {
+				b.fail(fmt.Errorf("%w: %s", ErrArgCount, expr.SQL))
+				return
+			}
+			b.arg(expr.Args[next])
+			next++
+		default:
+			b.sql.WriteRune(r)
+		}
+	}
+
+	if next != len(expr.Args) {
+		b.fail(fmt.Errorf("%w: %s", ErrArgCount, expr.SQL))
+	}
+}
+
+// value writes an Expr as SQL and any other value as an argument.
+func (b *builder) value(value any) {
+	if expr, ok := value.(Expr); ok {
+		b.expr(expr)
+		return
+	}
+
+	b.arg(value)
+}
+
+func (b *builder) where(exprs []Expr) {
+	if len(exprs) == 0 {
+		return
+	}
+
+	b.write(" WHERE ")
+	if len(exprs) == 1 {
+		b.expr(exprs[0])
+		return
+	}
+
+	b.expr(And(exprs...))
+}
+
+func (b *builder) returning(columns []string) {
+	if len(columns) > 0 {
+		b.write(" RETURNING ", strings.Join(columns, ", "))
+	}
+}
+
+func (b *builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+func (b *builder) result() (string, []any, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+
+	return b.sql.String(), b.args, nil
+}
@@ -0,0 +1,85 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		expr         Expr
+		expectedErr  error
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			name:         "placeholders",
+			expr:         E("a = ? AND b = ?", 1, 2),
+			expectedSQL:  "a = $1 AND b = $2",
+			expectedArgs: []any{1, 2},
+		},
+		{
+			name:         "escaped",
+			expr:         E("data ?? ? AND c = ?", "key", 3),
+			expectedSQL:  "data ? $1 AND c = $2",
+			expectedArgs: []any{"key", 3},
+		},
+		{
+			name:         "quoted",
+			expr:         E(`a = '?' AND "b?" = ?`, 1),
+			expectedSQL:  `a = '?' AND "b?" = $1`,
+			expectedArgs: []any{1},
+		},
+		{
+			name:         "and",
+			expr:         And(E("a = ?", 1), E("b = ?", 2)),
+			expectedSQL:  "(a = $1) AND (b = $2)",
+			expectedArgs: []any{1, 2},
+		},
+		{
+			name:         "or",
+			expr:         Or(E("a = ?", 1), Not(E("b = ?", 2))),
+			expectedSQL:  "(a = $1) OR (NOT (b = $2))",
+			expectedArgs: []any{1, 2},
+		},
+		{
+			name:        "empty and",
+			expr:        And(),
+			expectedSQL: "TRUE",
+		},
+		{
+			name:        "empty or",
+			expr:        Or(),
+			expectedSQL: "FALSE",
+		},
+		{
+			name:        "too few args",
+			expr:        E("a = ? AND b = ?", 1),
+			expectedErr: ErrArgCount,
+		},
+		{
+			name:        "too many args",
+			expr:        E("a = ?", 1, 2),
+			expectedErr: ErrArgCount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &builder{}
+			b.expr(tt.expr)
+			sql, args, err := b.result()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedSQL, sql)
+				assert.Equal(t, tt.expectedArgs, args)
+			}
+		})
+	}
+}
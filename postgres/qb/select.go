@@ -0,0 +1,202 @@
+package qb
+
+import (
+	"strings"
+)
+
+type join struct {
+	kind string
+	expr Expr
+}
+
+type SelectBuilder struct {
+	distinct bool
+	columns  []string
+	from     string
+	joins    []join
+	where    []Expr
+	groupBy  []string
+	having   []Expr
+	orderBy  []string
+	limit    *int64
+	offset   *int64
+	suffix   []Expr
+}
+
+func Select(columns ...string) *SelectBuilder {
+	return &SelectBuilder{columns: columns}
+}
+
+func (q *SelectBuilder) Distinct() *SelectBuilder {
+	q.distinct = true
+	return q
+}
+
+func (q *SelectBuilder) Columns(columns ...string) *SelectBuilder {
+	q.columns = append(q.columns, columns...)
+	return q
+}
+
+func (q *SelectBuilder) From(table string) *SelectBuilder {
+	q.from = table
+	return q
+}
+
+func (q *SelectBuilder) Join(sql string, args ...any) *SelectBuilder {
+	q.joins = append(q.joins, join{kind: "JOIN", expr: E(sql, args...)})
+	return q
+}
+
+func (q *SelectBuilder) LeftJoin(sql string, args ...any) *SelectBuilder {
+	q.joins = append(q.joins, join{kind: "LEFT JOIN", expr: E(sql, args...)})
+	return q
+}
+
+func (q *SelectBuilder) RightJoin(sql string, args ...any) *SelectBuilder {
+	q.joins = append(q.joins, join{kind: "RIGHT JOIN", expr: E(sql, args...)})
+	return q
+}
+
+func (q *SelectBuilder) FullJoin(sql string, args ...any) *SelectBuilder {
+	q.joins = append(q.joins, join{kind: "FULL JOIN", expr: E(sql, args...)})
+	return q
+}
+
+func (q *SelectBuilder) CrossJoin(sql string, args ...any) *SelectBuilder {
+	q.joins = append(q.joins, join{kind: "CROSS JOIN", expr: E(sql, args...)})
+	return q
+}
+
+// Where adds a condition that is combined with any other conditions using
+// AND.
+func (q *SelectBuilder) Where(sql string, args ...any) *SelectBuilder {
+	return q.WhereExpr(E(sql, args...))
+}
+
+func (q *SelectBuilder) WhereExpr(expr Expr) *SelectBuilder {
+	q.where = append(q.where, expr)
+	return q
+}
+
+func (q *SelectBuilder) GroupBy(columns ...string) *SelectBuilder {
+	q.groupBy = append(q.groupBy, columns...)
+	return q
+}
+
+func (q *SelectBuilder) Having(sql string, args ...any) *SelectBuilder {
+	q.having = append(q.having, E(sql, args...))
+	return q
+}
+
+func (q *SelectBuilder) OrderBy(columns ...string) *SelectBuilder {
+	q.orderBy = append(q.orderBy, columns...)
+	return q
+}
+
+func (q *SelectBuilder) Limit(limit int64) *SelectBuilder {
+	q.limit = &limit
+	return q
+}
+
+func (q *SelectBuilder) Offset(offset int64) *SelectBuilder {
+	q.offset = &offset
+	return q
+}
+
+// Suffix appends SQL to the end of the query, such as a locking clause.
+func (q *SelectBuilder) Suffix(sql string, args ...any) *SelectBuilder {
+	q.suffix = append(q.suffix, E(sql, args...))
+	return q
+}
+
+func (q *SelectBuilder) Build() (string, []any, error) {
+	b := &builder{}
+	q.build(b, q.limit, q.offset)
+	return b.result()
+}
+
+// BuildCount builds a query that counts the rows that would be selected,
+// ignoring the order, limit and offset.
+func (q *SelectBuilder) BuildCount() (string, []any, error) {
+	b := &builder{}
+	if q.distinct || len(q.groupBy) > 0 {
+		b.write("SELECT COUNT(*) FROM (")
+		q.buildSelect(b)
+		b.write(") AS counted")
+		return b.result()
+	}
+
+	b.write("SELECT COUNT(*)")
+	q.buildFrom(b)
+	return b.result()
+}
+
+// BuildPage builds the query with the limit and offset replaced to select a
+// single page of rows.
+func (q *SelectBuilder) BuildPage(firstItemIndex int64, pageSize int64) (string, []any, error) {
+	b := &builder{}
+	q.build(b, &pageSize, &firstItemIndex)
+	return b.result()
+}
+
+func (q *SelectBuilder) build(b *builder, limit *int64, offset *int64) {
+	q.buildSelect(b)
+
+	if len(q.orderBy) > 0 {
+		b.write(" ORDER BY ", strings.Join(q.orderBy, ", "))
+	}
+
+	if limit != nil {
+		b.write(" LIMIT ")
+		b.arg(*limit)
+	}
+
+	if offset != nil {
+		b.write(" OFFSET ")
+		b.arg(*offset)
+	}
+
+	for _, expr := range q.suffix {
+		b.write(" ")
+		b.expr(expr)
+	}
+}
+
+func (q *SelectBuilder) buildSelect(b *builder) {
+	if len(q.columns) == 0 {
+		b.fail(ErrNoColumns)
+		return
+	}
+
+	b.write("SELECT ")
+	if q.distinct {
+		b.write("DISTINCT ")
+	}
+	b.write(strings.Join(q.columns, ", "))
+
+	q.buildFrom(b)
+
+	if len(q.groupBy) > 0 {
+		b.write(" GROUP BY ", strings.Join(q.groupBy, ", "))
+	}
+
+	if len(q.having) > 0 {
+		b.write(" HAVING ")
+		b.expr(And(q.having...))
+	}
+}
+
+func (q *SelectBuilder) buildFrom(b *builder) {
+	if q.from == "" {
+		b.fail(ErrNoTable)
+		return
+	}
+
+	b.write(" FROM ", q.from)
+	for _, j := range q.joins {
+		b.write(" ", j.kind, " ")
+		b.expr(j.expr)
+	}
+
+	b.where(q.where)
+}
@@ -0,0 +1,106 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectBuild(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        *SelectBuilder
+		expectedErr  error
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			name:        "simple",
+			query:       Select("*").From("values"),
+			expectedSQL: "SELECT * FROM values",
+		},
+		{
+			name: "complete",
+			query: Select("v.id", "v.name").
+				Distinct().
+				From("values v").
+				Join("tags t ON t.value_id = v.id AND t.name = ?", "tag").
+				LeftJoin("notes n ON n.value_id = v.id").
+				Where("v.name = ?", "name").
+				WhereExpr(Or(E("v.value = ?", "a"), E("v.value = ?", "b"))).
+				GroupBy("v.id", "v.name").
+				Having("COUNT(*) > ?", 1).
+				OrderBy("v.id DESC").
+				Limit(10).
+				Offset(20).
+				Suffix("FOR UPDATE"),
+			expectedSQL: "SELECT DISTINCT v.id, v.name FROM values v " +
+				"JOIN tags t ON t.value_id = v.id AND t.name = $1 " +
+				"LEFT JOIN notes n ON n.value_id = v.id " +
+				"WHERE (v.name = $2) AND ((v.value = $3) OR (v.value = $4)) " +
+				"GROUP BY v.id, v.name HAVING COUNT(*) > $5 " +
+				"ORDER BY v.id DESC LIMIT $6 OFFSET $7 FOR UPDATE",
+			expectedArgs: []any{"tag", "name", "a", "b", 1, int64(10), int64(20)},
+		},
+		{
+			name:        "no columns",
+			query:       Select().From("values"),
+			expectedErr: ErrNoColumns,
+		},
+		{
+			name:        "no table",
+			query:       Select("*"),
+			expectedErr: ErrNoTable,
+		},
+		{
+			name:        "arg count",
+			query:       Select("*").From("values").Where("id = ?"),
+			expectedErr: ErrArgCount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.query.Build()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedSQL, sql)
+				assert.Equal(t, tt.expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestSelectBuildCount(t *testing.T) {
+	t.Parallel()
+
+	sql, args, err := Select("*").From("values").Where("name = ?", "name").OrderBy("id").Limit(10).BuildCount()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM values WHERE name = $1", sql)
+	assert.Equal(t, []any{"name"}, args)
+
+	sql, args, err = Select("name").From("values").Where("id > ?", 1).GroupBy("name").OrderBy("name").BuildCount()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM (SELECT name FROM values WHERE id > $1 GROUP BY name) AS counted", sql)
+	assert.Equal(t, []any{1}, args)
+}
+
+func TestSelectBuildPage(t *testing.T) {
+	t.Parallel()
+
+	q := Select("*").From("values").Where("name = ?", "name").OrderBy("id").Limit(1)
+	sql, args, err := q.BuildPage(30, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM values WHERE name = $1 ORDER BY id LIMIT $2 OFFSET $3", sql)
+	assert.Equal(t, []any{"name", int64(10), int64(30)}, args)
+
+	// The original limit is unchanged.
+	sql, args, err = q.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM values WHERE name = $1 ORDER BY id LIMIT $2", sql)
+	assert.Equal(t, []any{"name", int64(1)}, args)
+}
@@ -0,0 +1,104 @@
+package qb
+
+type assignment struct {
+	column string
+	value  any
+}
+
+type UpdateBuilder struct {
+	table     string
+	sets      []assignment
+	from      string
+	where     []Expr
+	returning []string
+}
+
+func Update(table string) *UpdateBuilder {
+	return &UpdateBuilder{table: table}
+}
+
+// Set assigns a value to the column. An Expr value is written as SQL instead
+// of being passed as an argument, such as E("count + ?", 1).
+func (q *UpdateBuilder) Set(column string, value any) *UpdateBuilder {
+	q.sets = append(q.sets, assignment{column: column, value: value})
+	return q
+}
+
+func (q *UpdateBuilder) From(table string) *UpdateBuilder {
+	q.from = table
+	return q
+}
+
+// Where adds a condition that is combined with any other conditions using
+// AND.
+func (q *UpdateBuilder) Where(sql string, args ...any) *UpdateBuilder {
+	return q.WhereExpr(E(sql, args...))
+}
+
+func (q *UpdateBuilder) WhereExpr(expr Expr) *UpdateBuilder {
+	q.where = append(q.where, expr)
+	return q
+}
+
+func (q *UpdateBuilder) Returning(columns ...string) *UpdateBuilder {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
+func (q *UpdateBuilder) Build() (string, []any, error) {
+	b := &builder{}
+
+	if q.table == "" {
+		b.fail(ErrNoTable)
+	}
+
+	if len(q.sets) == 0 {
+		b.fail(ErrNoColumns)
+	}
+
+	b.write("UPDATE ", q.table, " SET ")
+	for i, set := range q.sets {
+		if i > 0 {
+			b.write(", ")
+		}
+
+		b.write(set.column, " = ")
+		b.value(set.value)
+	}
+
+	if q.from != "" {
+		b.write(" FROM ", q.from)
+	}
+
+	b.where(q.where)
+	b.returning(q.returning)
+	return b.result()
+}
+
+// matching selects the rows that the update would change, ignoring its
+// assignments.
+func (q *UpdateBuilder) matching() *SelectBuilder {
+	from := q.table
+	if q.from != "" {
+		from += ", " + q.from
+	}
+
+	s := Select("1").From(from)
+	s.where = append(s.where, q.where...)
+	return s
+}
+
+// versioned returns a copy of the update that only changes rows where the
+// column equals the value. It returns every column when the update has no
+// RETURNING clause, so that a changed row can be told apart from no row.
+func (q *UpdateBuilder) versioned(column string, value any) *UpdateBuilder {
+	c := *q
+	c.sets = append([]assignment(nil), q.sets...)
+	c.where = append([]Expr(nil), q.where...)
+	c.returning = append([]string(nil), q.returning...)
+	if len(c.returning) == 0 {
+		c.returning = []string{"*"}
+	}
+
+	return c.Where(column+" = ?", value)
+}
@@ -0,0 +1,92 @@
+package qb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateBuild(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		query        *UpdateBuilder
+		expectedErr  error
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{
+			name:         "simple",
+			query:        Update("values").Set("name", "a").Where("id = ?", 1),
+			expectedSQL:  "UPDATE values SET name = $1 WHERE id = $2",
+			expectedArgs: []any{"a", 1},
+		},
+		{
+			name: "complete",
+			query: Update("values v").
+				Set("name", "a").
+				Set("value", E("o.value || ?", "b")).
+				From("others o").
+				Where("o.id = v.id").
+				Where("v.id = ?", 1).
+				Returning("v.id"),
+			expectedSQL:  "UPDATE values v SET name = $1, value = o.value || $2 FROM others o WHERE (o.id = v.id) AND (v.id = $3) RETURNING v.id",
+			expectedArgs: []any{"a", "b", 1},
+		},
+		{
+			name:        "no table",
+			query:       Update("").Set("name", "a"),
+			expectedErr: ErrNoTable,
+		},
+		{
+			name:        "no columns",
+			query:       Update("values"),
+			expectedErr: ErrNoColumns,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args, err := tt.query.Build()
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedSQL, sql)
+				assert.Equal(t, tt.expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestUpdateVersioned(t *testing.T) {
+	t.Parallel()
+
+	q := Update("values").Set("name", "a").Where("id = ?", 1)
+	sql, args, err := q.versioned("version", int64(2)).Set("version", E("version + 1")).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE values SET name = $1, version = version + 1 WHERE (id = $2) AND (version = $3) RETURNING *", sql)
+	assert.Equal(t, []any{"a", 1, int64(2)}, args)
+
+	// An explicit RETURNING clause is kept.
+	sql, _, err = Update("values").Set("name", "a").Returning("id").versioned("xmin", uint32(2)).Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE values SET name = $1 WHERE xmin = $2 RETURNING id", sql)
+
+	// The original update is unchanged.
+	sql, args, err = q.Build()
+	assert.NoError(t, err)
+	assert.Equal(t, "UPDATE values SET name = $1 WHERE id = $2", sql)
+	assert.Equal(t, []any{"a", 1}, args)
+}
+
+func TestUpdateMatching(t *testing.T) {
+	t.Parallel()
+
+	q := Update("values v").Set("name", "a").From("others o").Where("o.id = v.id").Where("v.id = ?", 1)
+	sql, args, err := q.matching().BuildCount()
+	assert.NoError(t, err)
+	assert.Equal(t, "SELECT COUNT(*) FROM values v, others o WHERE (o.id = v.id) AND (v.id = $1)", sql)
+	assert.Equal(t, []any{1}, args)
+}
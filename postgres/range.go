@@ -0,0 +1,621 @@
+package postgres
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeremybower/go-common"
+)
+
+var ErrInvalidRange = errors.New("invalid range")
+
+// RangeElement is the set of Go types that Range supports. They correspond to
+// the int4range, int8range, numrange, tsrange, tstzrange and daterange types.
+type RangeElement interface {
+	int32 | int64 | Decimal | time.Time | common.Date
+}
+
+type BoundType int
+
+const (
+	Unbounded BoundType = iota
+	Inclusive
+	Exclusive
+)
+
+// Range is a range of values with inclusive, exclusive or unbounded ends.
+// The zero value is unbounded at both ends and contains every value.
+type Range[T RangeElement] struct {
+	Lower     T
+	Upper     T
+	LowerType BoundType
+	UpperType BoundType
+	Empty     bool
+}
+
+// NewRange creates a range in its canonical form, so int32, int64 and
+// common.Date ranges have an inclusive lower bound and an exclusive upper
+// bound, like Postgres.
+func NewRange[T RangeElement](lower T, upper T, lowerType BoundType, upperType BoundType) Range[T] {
+	return Range[T]{Lower: lower, Upper: upper, LowerType: lowerType, UpperType: upperType}.Canonical()
+}
+
+func EmptyRange[T RangeElement]() Range[T] {
+	return Range[T]{Empty: true}
+}
+
+// Canonical returns the range in the form that Postgres stores it. Ranges of
+// the discrete int32, int64 and common.Date elements have an inclusive lower
+// bound and an exclusive upper bound, such as [2,5) for (1,4], and ranges
+// that contain no values are EmptyRange. Bounds that cannot be moved because
+// they are the largest value are left as they are.
+func (r Range[T]) Canonical() Range[T] {
+	if r.Empty {
+		return EmptyRange[T]()
+	}
+
+	if r.LowerType == Exclusive {
+		if next, ok := nextElement(r.Lower); ok {
+			r.Lower = next
+			r.LowerType = Inclusive
+		}
+	}
+
+	if r.UpperType == Inclusive {
+		if next, ok := nextElement(r.Upper); ok {
+			r.Upper = next
+			r.UpperType = Exclusive
+		}
+	}
+
+	if r.LowerType == Unbounded || r.UpperType == Unbounded {
+		return r
+	}
+
+	c := compareElements(r.Lower, r.Upper)
+	if c > 0 || (c == 0 && (r.LowerType == Exclusive || r.UpperType == Exclusive)) {
+		return EmptyRange[T]()
+	}
+
+	return r
+}
+
+// IsEmpty reports whether the range contains no values, either because it
+// is explicitly empty or because its bounds exclude every value, such as
+// (1,2) for a discrete range.
+func (r Range[T]) IsEmpty() bool {
+	return r.Canonical().Empty
+}
+
+func (r Range[T]) Contains(v T) bool {
+	if r.IsEmpty() {
+		return false
+	}
+
+	if r.LowerType != Unbounded {
+		c := compareElements(v, r.Lower)
+		if c < 0 || (c == 0 && r.LowerType == Exclusive) {
+			return false
+		}
+	}
+
+	if r.UpperType != Unbounded {
+		c := compareElements(v, r.Upper)
+		if c > 0 || (c == 0 && r.UpperType == Exclusive) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r Range[T]) Overlaps(other Range[T]) bool {
+	return !r.Intersect(other).IsEmpty()
+}
+
+// Intersect returns the values in both ranges as a canonical range.
+func (r Range[T]) Intersect(other Range[T]) Range[T] {
+	r = r.Canonical()
+	other = other.Canonical()
+	if r.Empty || other.Empty {
+		return EmptyRange[T]()
+	}
+
+	result := r
+	if compareLower(other, r) > 0 {
+		result.Lower = other.Lower
+		result.LowerType = other.LowerType
+	}
+
+	if compareUpper(other, r) < 0 {
+		result.Upper = other.Upper
+		result.UpperType = other.UpperType
+	}
+
+	return result.Canonical()
+}
+
+// String formats the range as a Postgres range literal, such as [1,5).
+func (r Range[T]) String() string {
+	if r.IsEmpty() {
+		return "empty"
+	}
+
+	var sb strings.Builder
+	if r.LowerType == Inclusive {
+		sb.WriteByte('[')
+	} else {
+		sb.WriteByte('(')
+	}
+
+	if r.LowerType != Unbounded {
+		sb.WriteString(formatElement(r.Lower))
+	}
+
+	sb.WriteByte(',')
+	if r.UpperType != Unbounded {
+		sb.WriteString(formatElement(r.Upper))
+	}
+
+	if r.UpperType == Inclusive {
+		sb.WriteByte(']')
+	} else {
+		sb.WriteByte(')')
+	}
+
+	return sb.String()
+}
+
+type rangeJSON[T RangeElement] struct {
+	Empty  bool   `json:"empty,omitempty"`
+	Lower  *T     `json:"lower,omitempty"`
+	Upper  *T     `json:"upper,omitempty"`
+	Bounds string `json:"bounds,omitempty"`
+}
+
+// MarshalJSON encodes the range as an object with the lower and upper values
+// and the bounds as a pair of brackets, such as {"lower":1,"upper":5,"bounds":"[)"}.
+// Unbounded ends are omitted and an empty range is encoded as {"empty":true}.
+func (r Range[T]) MarshalJSON() ([]byte, error) {
+	if r.IsEmpty() {
+		return json.Marshal(rangeJSON[T]{Empty: true})
+	}
+
+	v := rangeJSON[T]{Bounds: "()"}
+	if r.LowerType != Unbounded {
+		v.Lower = &r.Lower
+	}
+
+	if r.LowerType == Inclusive {
+		v.Bounds = "[" + v.Bounds[1:]
+	}
+
+	if r.UpperType != Unbounded {
+		v.Upper = &r.Upper
+	}
+
+	if r.UpperType == Inclusive {
+		v.Bounds = v.Bounds[:1] + "]"
+	}
+
+	return json.Marshal(v)
+}
+
+func (r *Range[T]) UnmarshalJSON(data []byte) error {
+	var v rangeJSON[T]
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidRange, err)
+	}
+
+	if v.Empty {
+		*r = EmptyRange[T]()
+		return nil
+	}
+
+	if len(v.Bounds) != 2 || !strings.ContainsRune("[(", rune(v.Bounds[0])) || !strings.ContainsRune("])", rune(v.Bounds[1])) {
+		return fmt.Errorf("%w: bounds %q", ErrInvalidRange, v.Bounds)
+	}
+
+	var result Range[T]
+	if v.Lower != nil {
+		result.Lower = *v.Lower
+		result.LowerType = boundType(v.Bounds[0] == '[')
+	}
+
+	if v.Upper != nil {
+		result.Upper = *v.Upper
+		result.UpperType = boundType(v.Bounds[1] == ']')
+	}
+
+	*r = result.Canonical()
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// pgx
+//-----------------------------------------------------------------------------
+
+func (r Range[T]) IsNull() bool {
+	return false
+}
+
+func (r Range[T]) BoundTypes() (lower, upper pgtype.BoundType) {
+	if r.IsEmpty() {
+		return pgtype.Empty, pgtype.Empty
+	}
+
+	return toPgBoundType(r.LowerType), toPgBoundType(r.UpperType)
+}
+
+func (r Range[T]) Bounds() (lower, upper any) {
+	return elementValue(r.Lower), elementValue(r.Upper)
+}
+
+func (r *Range[T]) ScanNull() error {
+	return fmt.Errorf("%w: cannot scan NULL into %T", ErrInvalidRange, r)
+}
+
+func (r *Range[T]) ScanBounds() (lowerTarget, upperTarget any) {
+	return elementTarget(&r.Lower), elementTarget(&r.Upper)
+}
+
+func (r *Range[T]) SetBoundTypes(lower, upper pgtype.BoundType) error {
+	if lower == pgtype.Empty || upper == pgtype.Empty {
+		*r = EmptyRange[T]()
+		return nil
+	}
+
+	lowerType, err := fromPgBoundType(lower)
+	if err != nil {
+		return err
+	}
+
+	upperType, err := fromPgBoundType(upper)
+	if err != nil {
+		return err
+	}
+
+	var zero T
+	if lowerType == Unbounded {
+		r.Lower = zero
+	}
+
+	if upperType == Unbounded {
+		r.Upper = zero
+	}
+
+	r.LowerType = lowerType
+	r.UpperType = upperType
+	r.Empty = false
+	*r = r.Canonical()
+	return nil
+}
+
+// Scan implements sql.Scanner for the text format of a range.
+func (r *Range[T]) Scan(src any) error {
+	var s string
+	switch src := src.(type) {
+	case string:
+		s = src
+	case []byte:
+		s = string(src)
+	case nil:
+		return r.ScanNull()
+	default:
+		return fmt.Errorf("%w: cannot scan %T into %T", ErrInvalidRange, src, r)
+	}
+
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "empty") {
+		*r = EmptyRange[T]()
+		return nil
+	}
+
+	if len(s) < 3 || !strings.ContainsRune("[(", rune(s[0])) || !strings.ContainsRune("])", rune(s[len(s)-1])) {
+		return fmt.Errorf("%w: %s", ErrInvalidRange, s)
+	}
+
+	lower, rest, err := parseBound(s[1:])
+	if err != nil || !strings.HasPrefix(rest, ",") {
+		return fmt.Errorf("%w: %s", ErrInvalidRange, s)
+	}
+
+	upper, rest, err := parseBound(rest[1:])
+	if err != nil || len(rest) != 1 {
+		return fmt.Errorf("%w: %s", ErrInvalidRange, s)
+	}
+
+	var result Range[T]
+	if lower != nil {
+		if result.Lower, err = parseElement[T](*lower); err != nil {
+			return err
+		}
+		result.LowerType = boundType(s[0] == '[')
+	}
+
+	if upper != nil {
+		if result.Upper, err = parseElement[T](*upper); err != nil {
+			return err
+		}
+		result.UpperType = boundType(s[len(s)-1] == ']')
+	}
+
+	*r = result.Canonical()
+	return nil
+}
+
+//-----------------------------------------------------------------------------
+// Helpers
+//-----------------------------------------------------------------------------
+
+func boundType(inclusive bool) BoundType {
+	if inclusive {
+		return Inclusive
+	}
+
+	return Exclusive
+}
+
+func toPgBoundType(t BoundType) pgtype.BoundType {
+	switch t {
+	case Inclusive:
+		return pgtype.Inclusive
+	case Exclusive:
+		return pgtype.Exclusive
+	}
+
+	return pgtype.Unbounded
+}
+
+func fromPgBoundType(t pgtype.BoundType) (BoundType, error) {
+	switch t {
+	case pgtype.Inclusive:
+		return Inclusive, nil
+	case pgtype.Exclusive:
+		return Exclusive, nil
+	case pgtype.Unbounded:
+		return Unbounded, nil
+	}
+
+	return Unbounded, fmt.Errorf("%w: bound type %q", ErrInvalidRange, rune(t))
+}
+
+// compareLower orders ranges by their lower bounds, where an unbounded lower
+// bound is the smallest.
+func compareLower[T RangeElement](a Range[T], b Range[T]) int {
+	switch {
+	case a.LowerType == Unbounded && b.LowerType == Unbounded:
+		return 0
+	case a.LowerType == Unbounded:
+		return -1
+	case b.LowerType == Unbounded:
+		return 1
+	}
+
+	if c := compareElements(a.Lower, b.Lower); c != 0 {
+		return c
+	}
+
+	// An inclusive lower bound starts before an exclusive one.
+	return cmp.Compare(a.LowerType, b.LowerType)
+}
+
+// compareUpper orders ranges by their upper bounds, where an unbounded upper
+// bound is the largest.
+func compareUpper[T RangeElement](a Range[T], b Range[T]) int {
+	switch {
+	case a.UpperType == Unbounded && b.UpperType == Unbounded:
+		return 0
+	case a.UpperType == Unbounded:
+		return 1
+	case b.UpperType == Unbounded:
+		return -1
+	}
+
+	if c := compareElements(a.Upper, b.Upper); c != 0 {
+		return c
+	}
+
+	// An exclusive upper bound ends before an inclusive one.
+	return cmp.Compare(b.UpperType, a.UpperType)
+}
+
+// nextElement returns the value after v for discrete elements.
+func nextElement[T RangeElement](v T) (T, bool) {
+	switch v := any(v).(type) {
+	case int32:
+		if v < math.MaxInt32 {
+			return any(v + 1).(T), true
+		}
+	case int64:
+		if v < math.MaxInt64 {
+			return any(v + 1).(T), true
+		}
+	case common.Date:
+		if v.Valid {
+			t := time.Date(v.Year, v.Month, v.Day+1, 0, 0, 0, 0, time.UTC)
+			return any(common.NewDate(t.Year(), t.Month(), t.Day())).(T), true
+		}
+	}
+
+	var zero T
+	return zero, false
+}
+
+func compareElements[T RangeElement](a T, b T) int {
+	switch a := any(a).(type) {
+	case int32:
+		return cmp.Compare(a, any(b).(int32))
+	case int64:
+		return cmp.Compare(a, any(b).(int64))
+	case Decimal:
+		return a.Cmp(any(b).(Decimal))
+	case time.Time:
+		return a.Compare(any(b).(time.Time))
+	case common.Date:
+		b := any(b).(common.Date)
+		if c := cmp.Compare(a.Year, b.Year); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.Month, b.Month); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Day, b.Day)
+	}
+
+	panic(fmt.Sprintf("unsupported range element %T", a))
+}
+
+func formatElement[T RangeElement](v T) string {
+	switch v := any(v).(type) {
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case Decimal:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case common.Date:
+		return v.String()
+	}
+
+	panic(fmt.Sprintf("unsupported range element %T", v))
+}
+
+func parseElement[T RangeElement](s string) (T, error) {
+	var zero T
+	var v any
+	var err error
+	switch any(zero).(type) {
+	case int32:
+		var n int64
+		n, err = strconv.ParseInt(s, 10, 32)
+		v = int32(n)
+	case int64:
+		v, err = strconv.ParseInt(s, 10, 64)
+	case Decimal:
+		v, err = ParseDecimal(s)
+	case time.Time:
+		v, err = parseTimestamp(s)
+	case common.Date:
+		var d common.Date
+		err = d.UnmarshalJSON([]byte(strconv.Quote(s)))
+		if err == nil && !d.Valid {
+			err = common.ErrInvalidDate
+		}
+		v = d
+	}
+
+	if err != nil {
+		return zero, fmt.Errorf("%w: %w", ErrInvalidRange, err)
+	}
+
+	return v.(T), nil
+}
+
+// parseTimestamp parses the text formats that Postgres uses for timestamp and
+// timestamptz values, falling back to RFC 3339.
+func parseTimestamp(s string) (time.Time, error) {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05.999999999Z07",
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999Z07:00:00",
+		"2006-01-02 15:04:05.999999999",
+		time.RFC3339Nano,
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid timestamp: %s", s)
+}
+
+// parseBound parses an optionally quoted bound from the start of s and
+// returns the remainder. A missing bound is returned as nil.
+func parseBound(s string) (*string, string, error) {
+	if len(s) == 0 {
+		return nil, s, ErrInvalidRange
+	}
+
+	if s[0] == ',' || s[0] == ')' || s[0] == ']' {
+		return nil, s, nil
+	}
+
+	var buf bytes.Buffer
+	quoted := false
+	i := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			buf.WriteByte(s[i])
+		case c == '"' && quoted && i+1 < len(s) && s[i+1] == '"':
+			i++
+			buf.WriteByte('"')
+		case c == '"':
+			quoted = !quoted
+		case !quoted && (c == ',' || c == ')' || c == ']'):
+			str := buf.String()
+			return &str, s[i:], nil
+		default:
+			buf.WriteByte(c)
+		}
+	}
+
+	return nil, "", ErrInvalidRange
+}
+
+// elementTarget returns a scan target for the element. Dates are scanned
+// through a wrapper because common.Date does not depend on pgx.
+func elementTarget[T RangeElement](v *T) any {
+	if d, ok := any(v).(*common.Date); ok {
+		return &dateTarget{d}
+	}
+
+	return v
+}
+
+func elementValue[T RangeElement](v T) any {
+	if d, ok := any(v).(common.Date); ok {
+		return dateValue{d}
+	}
+
+	return v
+}
+
+type dateTarget struct {
+	d *common.Date
+}
+
+func (t *dateTarget) ScanDate(v pgtype.Date) error {
+	if !v.Valid {
+		return fmt.Errorf("%w: cannot scan NULL into %T", common.ErrInvalidDate, t.d)
+	}
+
+	d, err := toDate(v)
+	if err != nil {
+		return err
+	}
+
+	*t.d = d
+	return nil
+}
+
+type dateValue struct {
+	d common.Date
+}
+
+func (v dateValue) DateValue() (pgtype.Date, error) {
+	return FromDate(&v.d)
+}
@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeremybower/go-common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRangeIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, EmptyRange[int32]().IsEmpty())
+	assert.False(t, Range[int32]{}.IsEmpty())
+	assert.False(t, NewRange[int32](1, 1, Inclusive, Inclusive).IsEmpty())
+	assert.True(t, NewRange[int32](1, 1, Inclusive, Exclusive).IsEmpty())
+	assert.True(t, NewRange[int32](2, 1, Inclusive, Inclusive).IsEmpty())
+	assert.False(t, NewRange[int32](2, 1, Inclusive, Unbounded).IsEmpty())
+
+	// Discrete ranges are empty when no value lies between the bounds.
+	assert.True(t, Range[int32]{Lower: 1, Upper: 2, LowerType: Exclusive, UpperType: Exclusive}.IsEmpty())
+	assert.True(t, Range[common.Date]{Lower: common.NewDate(2024, 1, 1), Upper: common.NewDate(2024, 1, 2), LowerType: Exclusive, UpperType: Exclusive}.IsEmpty())
+	assert.False(t, Range[Decimal]{Lower: MustNewDecimal(1, 0), Upper: MustNewDecimal(2, 0), LowerType: Exclusive, UpperType: Exclusive}.IsEmpty())
+}
+
+func TestRangeCanonical(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, Range[int32]{Lower: 2, Upper: 6, LowerType: Inclusive, UpperType: Exclusive}, Range[int32]{Lower: 1, Upper: 5, LowerType: Exclusive, UpperType: Inclusive}.Canonical())
+	assert.Equal(t, Range[int64]{Lower: 2, LowerType: Inclusive}, Range[int64]{Lower: 1, LowerType: Exclusive}.Canonical())
+	assert.Equal(t, EmptyRange[int32](), Range[int32]{Lower: 1, Upper: 2, LowerType: Exclusive, UpperType: Exclusive}.Canonical())
+
+	// The largest value cannot be moved past.
+	assert.Equal(t, Range[int32]{Lower: 1, Upper: math.MaxInt32, LowerType: Inclusive, UpperType: Inclusive}, Range[int32]{Lower: 1, Upper: math.MaxInt32, LowerType: Inclusive, UpperType: Inclusive}.Canonical())
+
+	dates := Range[common.Date]{Lower: common.NewDate(2024, 1, 31), Upper: common.NewDate(2024, 2, 29), LowerType: Exclusive, UpperType: Inclusive}
+	assert.Equal(t, Range[common.Date]{Lower: common.NewDate(2024, 2, 1), Upper: common.NewDate(2024, 3, 1), LowerType: Inclusive, UpperType: Exclusive}, dates.Canonical())
+
+	// Continuous ranges are unchanged.
+	times := Range[time.Time]{Lower: time.Unix(1, 0), Upper: time.Unix(2, 0), LowerType: Exclusive, UpperType: Inclusive}
+	assert.Equal(t, times, times.Canonical())
+}
+
+func TestRangeContains(t *testing.T) {
+	t.Parallel()
+
+	r := NewRange[int32](1, 5, Inclusive, Exclusive)
+	assert.False(t, r.Contains(0))
+	assert.True(t, r.Contains(1))
+	assert.True(t, r.Contains(4))
+	assert.False(t, r.Contains(5))
+
+	r = NewRange[int32](1, 5, Exclusive, Inclusive)
+	assert.False(t, r.Contains(1))
+	assert.True(t, r.Contains(5))
+
+	r = NewRange[int32](0, 5, Unbounded, Exclusive)
+	assert.True(t, r.Contains(-1000))
+	assert.False(t, r.Contains(5))
+
+	assert.True(t, Range[int32]{}.Contains(42))
+	assert.False(t, EmptyRange[int32]().Contains(42))
+}
+
+func TestRangeOverlapsAndIntersect(t *testing.T) {
+	t.Parallel()
+
+	jan := func(day int) time.Time { return time.Date(2024, 1, day, 0, 0, 0, 0, time.UTC) }
+
+	a := NewRange(jan(1), jan(5), Inclusive, Exclusive)
+	b := NewRange(jan(3), jan(8), Inclusive, Exclusive)
+	c := NewRange(jan(5), jan(8), Inclusive, Exclusive)
+	d := NewRange(jan(5), time.Time{}, Inclusive, Unbounded)
+
+	assert.True(t, a.Overlaps(b))
+	assert.False(t, a.Overlaps(c))
+	assert.True(t, b.Overlaps(c))
+	assert.True(t, b.Overlaps(d))
+	assert.False(t, a.Overlaps(EmptyRange[time.Time]()))
+
+	assert.Equal(t, NewRange(jan(3), jan(5), Inclusive, Exclusive), a.Intersect(b))
+	assert.Equal(t, NewRange(jan(3), jan(5), Inclusive, Exclusive), b.Intersect(a))
+	assert.Equal(t, NewRange(jan(5), jan(8), Inclusive, Exclusive), b.Intersect(d))
+	assert.True(t, a.Intersect(c).IsEmpty())
+	assert.Equal(t, a, a.Intersect(Range[time.Time]{}))
+
+	e := NewRange[int64](1, 5, Inclusive, Inclusive)
+	f := NewRange[int64](1, 5, Exclusive, Exclusive)
+	assert.Equal(t, f, e.Intersect(f))
+	assert.Equal(t, f, f.Intersect(e))
+
+	// Postgres reports int4range(1, 2, '()') as empty, so it overlaps nothing.
+	g := Range[int32]{Lower: 1, Upper: 2, LowerType: Exclusive, UpperType: Exclusive}
+	assert.False(t, g.Overlaps(Range[int32]{}))
+	assert.False(t, Range[int32]{}.Overlaps(g))
+
+	// Adjacent discrete ranges do not overlap.
+	h := Range[int32]{Lower: 1, Upper: 3, LowerType: Inclusive, UpperType: Inclusive}
+	i := Range[int32]{Lower: 3, Upper: 5, LowerType: Exclusive, UpperType: Inclusive}
+	assert.False(t, h.Overlaps(i))
+	assert.Equal(t, NewRange[int32](3, 3, Inclusive, Inclusive), h.Intersect(Range[int32]{Lower: 2, Upper: 5, LowerType: Exclusive, UpperType: Exclusive}))
+}
+
+func TestRangeElements(t *testing.T) {
+	t.Parallel()
+
+	dates := NewRange(common.NewDate(2024, 1, 1), common.NewDate(2024, 2, 1), Inclusive, Exclusive)
+	assert.True(t, dates.Contains(common.NewDate(2024, 1, 31)))
+	assert.False(t, dates.Contains(common.NewDate(2024, 2, 1)))
+	assert.False(t, dates.Contains(common.NewDate(2023, 12, 31)))
+
+	decimals := NewRange(MustParseDecimal("0.5"), MustParseDecimal("1.50"), Inclusive, Inclusive)
+	assert.True(t, decimals.Contains(MustParseDecimal("1.5")))
+	assert.False(t, decimals.Contains(MustParseDecimal("1.51")))
+}
+
+func TestRangeString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "[1,5)", NewRange[int32](1, 5, Inclusive, Exclusive).String())
+	assert.Equal(t, "(,6)", NewRange[int32](0, 5, Unbounded, Inclusive).String())
+	assert.Equal(t, "(,5]", NewRange(Decimal{}, MustNewDecimal(5, 0), Unbounded, Inclusive).String())
+	assert.Equal(t, "(,)", Range[int32]{}.String())
+	assert.Equal(t, "empty", EmptyRange[int32]().String())
+	assert.Equal(t, "[2024-01-01,2024-02-01)", NewRange(common.NewDate(2024, 1, 1), common.NewDate(2024, 2, 1), Inclusive, Exclusive).String())
+}
+
+func TestRangeJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		r    Range[int32]
+		json string
+	}{
+		{"bounded", NewRange[int32](1, 5, Inclusive, Exclusive), `{"lower":1,"upper":5,"bounds":"[)"}`},
+		{"canonical", NewRange[int32](1, 5, Exclusive, Inclusive), `{"lower":2,"upper":6,"bounds":"[)"}`},
+		{"unbounded lower", NewRange[int32](0, 5, Unbounded, Exclusive), `{"upper":5,"bounds":"()"}`},
+		{"unbounded", Range[int32]{}, `{"bounds":"()"}`},
+		{"empty", EmptyRange[int32](), `{"empty":true}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(tt.r)
+			require.NoError(t, err)
+			assert.Equal(t, tt.json, string(b))
+
+			var r Range[int32]
+			require.NoError(t, json.Unmarshal(b, &r))
+			assert.Equal(t, tt.r, r)
+		})
+	}
+
+	var r Range[int32]
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"lower":1,"bounds":"<>"}`), &r), ErrInvalidRange)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`[]`), &r), ErrInvalidRange)
+}
+
+func TestRangeScan(t *testing.T) {
+	t.Parallel()
+
+	var r Range[int32]
+	require.NoError(t, r.Scan("[1,5)"))
+	assert.Equal(t, NewRange[int32](1, 5, Inclusive, Exclusive), r)
+
+	require.NoError(t, r.Scan([]byte("(,5]")))
+	assert.Equal(t, NewRange[int32](0, 5, Unbounded, Inclusive), r)
+
+	require.NoError(t, r.Scan("empty"))
+	assert.Equal(t, EmptyRange[int32](), r)
+
+	var tr Range[time.Time]
+	require.NoError(t, tr.Scan(`["2024-01-02 03:04:05.123456+00","2024-01-03 03:04:05+02")`))
+	assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC).Equal(tr.Lower))
+	assert.True(t, time.Date(2024, 1, 3, 1, 4, 5, 0, time.UTC).Equal(tr.Upper))
+
+	var dr Range[common.Date]
+	require.NoError(t, dr.Scan(`[2024-01-01,)`))
+	assert.Equal(t, NewRange(common.NewDate(2024, 1, 1), common.Date{}, Inclusive, Unbounded), dr)
+
+	assert.ErrorIs(t, r.Scan(nil), ErrInvalidRange)
+	assert.ErrorIs(t, r.Scan(1), ErrInvalidRange)
+	assert.ErrorIs(t, r.Scan("[1,5"), ErrInvalidRange)
+	assert.ErrorIs(t, r.Scan("[1;5)"), ErrInvalidRange)
+	assert.ErrorIs(t, r.Scan("[a,5)"), ErrInvalidRange)
+	assert.ErrorIs(t, dr.Scan("[2024-02-30,)"), ErrInvalidRange)
+}
+
+func TestRangePgx(t *testing.T) {
+	t.Parallel()
+
+	var r Range[int32]
+	lower, upper := r.ScanBounds()
+	*lower.(*int32) = 1
+	*upper.(*int32) = 5
+	require.NoError(t, r.SetBoundTypes(pgtype.Inclusive, pgtype.Unbounded))
+	assert.Equal(t, NewRange[int32](1, 0, Inclusive, Unbounded), r)
+
+	lowerType, upperType := r.BoundTypes()
+	assert.Equal(t, pgtype.Inclusive, lowerType)
+	assert.Equal(t, pgtype.Unbounded, upperType)
+
+	require.NoError(t, r.SetBoundTypes(pgtype.Empty, pgtype.Empty))
+	assert.Equal(t, EmptyRange[int32](), r)
+
+	lowerType, upperType = r.BoundTypes()
+	assert.Equal(t, pgtype.Empty, lowerType)
+	assert.Equal(t, pgtype.Empty, upperType)
+
+	assert.ErrorIs(t, r.SetBoundTypes('x', pgtype.Inclusive), ErrInvalidRange)
+	assert.ErrorIs(t, r.ScanNull(), ErrInvalidRange)
+	assert.False(t, r.IsNull())
+
+	var dr Range[common.Date]
+	dateLower, _ := dr.ScanBounds()
+	require.NoError(t, dateLower.(pgtype.DateScanner).ScanDate(pgtype.Date{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}))
+	assert.Equal(t, common.NewDate(2024, 1, 2), dr.Lower)
+	assert.ErrorIs(t, dateLower.(pgtype.DateScanner).ScanDate(pgtype.Date{InfinityModifier: pgtype.Infinity, Valid: true}), ErrInfiniteTime)
+	assert.ErrorIs(t, dateLower.(pgtype.DateScanner).ScanDate(pgtype.Date{}), common.ErrInvalidDate)
+
+	dateValue, _ := dr.Bounds()
+	d, err := dateValue.(pgtype.DateValuer).DateValue()
+	require.NoError(t, err)
+	assert.Equal(t, pgtype.Date{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Valid: true}, d)
+}
+
+func TestRangeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := dbPool.Exec(ctx, `
+		CREATE EXTENSION IF NOT EXISTS btree_gist;
+		CREATE TABLE bookings (
+			id BIGSERIAL PRIMARY KEY,
+			room INT4 NOT NULL,
+			seats INT4RANGE NOT NULL,
+			during TSTZRANGE NOT NULL,
+			days DATERANGE NOT NULL,
+			EXCLUDE USING gist (room WITH =, during WITH &&)
+		);`)
+	require.NoError(t, err)
+
+	type bookingRow struct {
+		ID     int64              `db:"id"`
+		Room   int32              `db:"room"`
+		Seats  Range[int32]       `db:"seats"`
+		During Range[time.Time]   `db:"during"`
+		Days   Range[common.Date] `db:"days"`
+	}
+
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	seats := NewRange[int32](1, 4, Inclusive, Inclusive)
+	during := NewRange(start, start.Add(time.Hour), Inclusive, Exclusive)
+	days := NewRange(common.NewDate(2024, 1, 1), common.Date{}, Inclusive, Unbounded)
+
+	insert := "INSERT INTO bookings (room, seats, during, days) VALUES ($1, $2, $3, $4) RETURNING *;"
+	row, err := ReadOne[bookingRow](ctx, dbPool, insert, 1, seats, during, days)
+	require.NoError(t, err)
+	assert.Equal(t, NewRange[int32](1, 5, Inclusive, Exclusive), row.Seats)
+	assert.True(t, row.During.Lower.Equal(start))
+	assert.Equal(t, Exclusive, row.During.UpperType)
+	assert.Equal(t, days, row.Days)
+
+	// The exclusion constraint rejects an overlapping booking.
+	overlapping := NewRange(start.Add(30*time.Minute), start.Add(2*time.Hour), Inclusive, Exclusive)
+	_, err = ReadOne[bookingRow](ctx, dbPool, insert, 1, seats, overlapping, days)
+	assert.Error(t, err)
+
+	rows, err := ReadMany[bookingRow](ctx, dbPool, "SELECT * FROM bookings WHERE during && $1;", overlapping)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.True(t, rows[0].During.Overlaps(overlapping))
+
+	var empty Range[int32]
+	err = dbPool.QueryRow(ctx, "SELECT 'empty'::int4range;").Scan(&empty)
+	require.NoError(t, err)
+	assert.True(t, empty.IsEmpty())
+}
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jeremybower/go-common/postgres"
+)
+
+var ErrRateLimit = errors.New("postgres rate limit")
+var ErrInvalidCost = fmt.Errorf("%w: cost must be between 1 and the limit", ErrRateLimit)
+
+// Migration creates the rate_limits table.
+const Migration = `
+CREATE TABLE IF NOT EXISTS rate_limits (
+	key TEXT PRIMARY KEY,
+	tokens DOUBLE PRECISION NOT NULL,
+	allowed BOOLEAN NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+`
+
+// Limiter is a token bucket whose state is kept in Postgres, so that limits
+// hold across replicas. Each key's bucket holds up to limit tokens and
+// refills at limit tokens per period. Limiters with different settings
+// should not share keys.
+type Limiter struct {
+	querier postgres.Querier
+	limit   int64
+	period  time.Duration
+}
+
+// NewLimiter panics unless the limit and period are positive.
+func NewLimiter(querier postgres.Querier, limit int64, period time.Duration) *Limiter {
+	if limit <= 0 {
+		panic("rate limit must be positive")
+	}
+
+	if period <= 0 {
+		panic("rate limit period must be positive")
+	}
+
+	return &Limiter{querier: querier, limit: limit, period: period}
+}
+
+type Result struct {
+	Allowed bool
+
+	// Remaining is the number of tokens left in the bucket.
+	Remaining int64
+
+	// RetryAfter is how long until the cost is available, or zero when the
+	// request was allowed. It can be passed to backoff.Backoff.WaitAfter.
+	RetryAfter time.Duration
+}
+
+// refilled is the number of tokens in the bucket after refilling it for the
+// time since it was last updated. The time is read once per statement with
+// clock_timestamp, as EXCLUDED.updated_at, rather than with now(), which is
+// the start of the transaction and can be earlier than the last update.
+const refilled = "LEAST($2::float8, r.tokens + GREATEST(0, EXTRACT(EPOCH FROM EXCLUDED.updated_at - r.updated_at))::float8 * $3::float8)"
+
+type bucket struct {
+	Tokens  float64 `db:"tokens"`
+	Allowed bool    `db:"allowed"`
+}
+
+// Allow takes cost tokens from the key's bucket when it has enough. The
+// bucket is refilled and taken from in a single statement, so concurrent
+// requests cannot overdraw it. It fails with ErrInvalidCost unless the cost
+// is between 1 and the limit.
+func (l *Limiter) Allow(ctx context.Context, key string, cost int64) (Result, error) {
+	if cost < 1 || cost > l.limit {
+		return Result{}, ErrInvalidCost
+	}
+
+	// Refill the bucket and take the cost.
+	rate := l.rate()
+	b, err := postgres.ReadOne[bucket](ctx, l.querier, `
+		INSERT INTO rate_limits AS r (key, tokens, allowed, updated_at)
+		VALUES ($1, $2::float8 - $4::float8, TRUE, clock_timestamp())
+		ON CONFLICT (key) DO UPDATE SET
+			tokens = CASE
+				WHEN `+refilled+` >= $4::float8 THEN `+refilled+` - $4::float8
+				ELSE `+refilled+`
+			END,
+			allowed = `+refilled+` >= $4::float8,
+			updated_at = GREATEST(r.updated_at, EXCLUDED.updated_at)
+		RETURNING tokens, allowed`,
+		key, float64(l.limit), rate, float64(cost),
+	)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Success.
+	return result(b, cost, rate), nil
+}
+
+// Purge removes the buckets that have been idle long enough to be full,
+// which behave the same as missing buckets.
+func (l *Limiter) Purge(ctx context.Context) (int64, error) {
+	return postgres.Exec(ctx, l.querier,
+		"DELETE FROM rate_limits WHERE updated_at < now() - $1::interval", l.period)
+}
+
+// rate is the number of tokens refilled per second.
+func (l *Limiter) rate() float64 {
+	return float64(l.limit) / l.period.Seconds()
+}
+
+func result(b *bucket, cost int64, rate float64) Result {
+	r := Result{
+		Allowed:   b.Allowed,
+		Remaining: int64(math.Floor(b.Tokens)),
+	}
+
+	if !b.Allowed {
+		seconds := (float64(cost) - b.Tokens) / rate
+		r.RetryAfter = time.Duration(math.Ceil(seconds * float64(time.Second)))
+	}
+
+	return r
+}
@@ -0,0 +1,147 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common/internal/pgtest"
+	"github.com/jeremybower/go-common/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func databasePoolForTesting(t *testing.T) *pgxpool.Pool {
+	return pgtest.Pool(t, Migration)
+}
+
+func TestNewLimiterInvalid(t *testing.T) {
+	t.Parallel()
+
+	assert.PanicsWithValue(t, "rate limit must be positive", func() { NewLimiter(nil, 0, time.Minute) })
+	assert.PanicsWithValue(t, "rate limit must be positive", func() { NewLimiter(nil, -1, time.Minute) })
+	assert.PanicsWithValue(t, "rate limit period must be positive", func() { NewLimiter(nil, 10, 0) })
+	assert.PanicsWithValue(t, "rate limit period must be positive", func() { NewLimiter(nil, 10, -time.Second) })
+}
+
+func TestAllowInvalidCost(t *testing.T) {
+	t.Parallel()
+
+	l := NewLimiter(nil, 10, time.Minute)
+
+	_, err := l.Allow(context.Background(), "key", 0)
+	assert.ErrorIs(t, err, ErrInvalidCost)
+
+	_, err = l.Allow(context.Background(), "key", -1)
+	assert.ErrorIs(t, err, ErrInvalidCost)
+
+	_, err = l.Allow(context.Background(), "key", 11)
+	assert.ErrorIs(t, err, ErrInvalidCost)
+}
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+
+	rate := NewLimiter(nil, 10, time.Minute).rate()
+
+	r := result(&bucket{Tokens: 4.5, Allowed: true}, 2, rate)
+	assert.Equal(t, Result{Allowed: true, Remaining: 4}, r)
+
+	r = result(&bucket{Tokens: 0.5, Allowed: false}, 2, rate)
+	assert.Equal(t, Result{Allowed: false, Remaining: 0, RetryAfter: 9 * time.Second}, r)
+}
+
+func TestAllow(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	l := NewLimiter(dbPool, 3, time.Hour)
+
+	r, err := l.Allow(ctx, "tenant:a", 2)
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, int64(1), r.Remaining)
+
+	r, err = l.Allow(ctx, "tenant:a", 1)
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+	assert.Equal(t, int64(0), r.Remaining)
+
+	r, err = l.Allow(ctx, "tenant:a", 1)
+	require.NoError(t, err)
+	assert.False(t, r.Allowed)
+	assert.Equal(t, int64(0), r.Remaining)
+	assert.InDelta(t, 20*time.Minute, r.RetryAfter, float64(time.Second))
+
+	// Keys have their own buckets.
+	r, err = l.Allow(ctx, "tenant:b", 3)
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+
+	// The bucket refills over time.
+	_, err = postgres.Exec(ctx, dbPool, "UPDATE rate_limits SET updated_at = updated_at - interval '20 minutes' WHERE key = 'tenant:a'")
+	require.NoError(t, err)
+
+	r, err = l.Allow(ctx, "tenant:a", 1)
+	require.NoError(t, err)
+	assert.True(t, r.Allowed)
+}
+
+func TestAllowConcurrent(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	l := NewLimiter(dbPool, 5, time.Hour)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	allowed := 0
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			r, err := l.Allow(ctx, "key", 1)
+			assert.NoError(t, err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if r.Allowed {
+				allowed++
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 5, allowed)
+}
+
+func TestPurge(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	l := NewLimiter(dbPool, 5, time.Minute)
+
+	_, err := l.Allow(ctx, "idle", 1)
+	require.NoError(t, err)
+	_, err = l.Allow(ctx, "active", 1)
+	require.NoError(t, err)
+
+	_, err = postgres.Exec(ctx, dbPool, "UPDATE rate_limits SET updated_at = updated_at - interval '2 minutes' WHERE key = 'idle'")
+	require.NoError(t, err)
+
+	c, err := l.Purge(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+}
@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type enumDefinition interface {
+	Name() string
+	Labels() []string
+}
+
+// Types loads composite and enum types by name, along with their arrays, and
+// registers them in the type map of each connection.
+type Types struct {
+	mu    sync.RWMutex
+	names []string
+	enums []enumDefinition
+}
+
+func NewTypes() *Types {
+	return &Types{}
+}
+
+// Add registers composite or enum types by name. Names may be schema
+// qualified.
+func (t *Types) Add(names ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.names = append(t.names, names...)
+}
+
+// AddEnum registers enum types whose Go values are checked against the
+// labels in the database when connecting. Labels that exist only in the
+// database are allowed so that values can be added before the code that uses
+// them is deployed.
+func (t *Types) AddEnum(enums ...enumDefinition) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, e := range enums {
+		t.names = append(t.names, e.Name())
+		t.enums = append(t.enums, e)
+	}
+}
+
+// AfterConnect loads and registers every type on the connection. It is
+// intended to be assigned to pgxpool.Config.AfterConnect.
+func (t *Types) AfterConnect(ctx context.Context, conn *pgx.Conn) error {
+	t.mu.RLock()
+	names := slices.Clone(t.names)
+	enums := slices.Clone(t.enums)
+	t.mu.RUnlock()
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	// Load the types and their arrays, including any dependencies.
+	typeNames := make([]string, 0, len(names)*2)
+	for _, name := range names {
+		typeNames = append(typeNames, name, arrayTypeName(name))
+	}
+
+	types, err := conn.LoadTypes(ctx, typeNames)
+	if err != nil {
+		return err
+	}
+
+	conn.TypeMap().RegisterTypes(types)
+
+	// Check the enum values.
+	for _, e := range enums {
+		if err := checkEnum(ctx, conn, e); err != nil {
+			return err
+		}
+	}
+
+	// Success.
+	return nil
+}
+
+func checkEnum(ctx context.Context, conn *pgx.Conn, e enumDefinition) error {
+	rows, err := conn.Query(ctx, "SELECT enumlabel FROM pg_enum WHERE enumtypid = $1::text::regtype", e.Name())
+	if err != nil {
+		return err
+	}
+
+	labels, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for _, label := range e.Labels() {
+		if !slices.Contains(labels, label) {
+			missing = append(missing, label)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s: %s", ErrEnumMismatch, e.Name(), strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// arrayTypeName returns the name of the array type, which Postgres prefixes
+// with an underscore.
+func arrayTypeName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i+1] + "_" + name[i+1:]
+	}
+
+	return "_" + name
+}
@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArrayTypeName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "_address", arrayTypeName("address"))
+	assert.Equal(t, "public._address", arrayTypeName("public.address"))
+}
+
+func TestTypesAfterConnect(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := dbPool.Exec(ctx, `
+		CREATE TYPE mood AS ENUM ('happy', 'sad', 'bored');
+		CREATE TYPE address AS (street TEXT, city TEXT, mood mood);
+	`)
+	require.NoError(t, err)
+
+	conn, err := dbPool.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+
+	types := NewTypes()
+	types.AddEnum(moodEnum)
+	types.Add("address")
+	require.NoError(t, types.AfterConnect(ctx, conn.Conn()))
+
+	type address struct {
+		Street string
+		City   string
+		Mood   mood
+	}
+
+	var addresses []address
+	var moods []mood
+	err = conn.QueryRow(ctx, `
+		SELECT ARRAY[ROW('1 Main St', 'Springfield', 'happy')::address], ARRAY['sad', 'happy']::mood[]
+	`).Scan(&addresses, &moods)
+	require.NoError(t, err)
+	assert.Equal(t, []address{{Street: "1 Main St", City: "Springfield", Mood: moodHappy}}, addresses)
+	assert.Equal(t, []mood{moodSad, moodHappy}, moods)
+
+	var back address
+	err = conn.QueryRow(ctx, "SELECT $1::address", addresses[0]).Scan(&back)
+	require.NoError(t, err)
+	assert.Equal(t, addresses[0], back)
+}
+
+func TestTypesAfterConnectEnumMismatch(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := dbPool.Exec(ctx, "CREATE TYPE mood AS ENUM ('happy');")
+	require.NoError(t, err)
+
+	conn, err := dbPool.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+
+	types := NewTypes()
+	types.AddEnum(moodEnum)
+	err = types.AfterConnect(ctx, conn.Conn())
+	assert.ErrorIs(t, err, ErrEnumMismatch)
+	assert.ErrorContains(t, err, "mood: sad")
+}
+
+func TestTypesAfterConnectEmpty(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, NewTypes().AfterConnect(context.Background(), (*pgx.Conn)(nil)))
+}
@@ -0,0 +1,250 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const primaryKey = contextKey("primary")
+
+// The lag is zero when the replica has replayed everything it has received,
+// otherwise it is the age of the last replayed transaction. Servers that are
+// not replicas report zero.
+const replicaLagSQL = `SELECT COALESCE(
+	CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END,
+	0)::float8`
+
+// RoutingQuerier sends reads to replicas and everything else to the primary.
+// Only queries that start with SELECT are reads, so statements such as
+// INSERT ... RETURNING always use the primary. Queries with a locking clause,
+// such as FOR UPDATE, and queries that call well-known functions with side
+// effects, such as nextval and pg_advisory_lock, also use the primary. Use
+// WithPrimary to read your own writes or to call other functions that write.
+//
+// A replica that fails with a connection error is ejected for ejectFor and
+// the read is retried on the primary. When maxLag is positive, replicas whose
+// lag, as measured by CheckReplicas, exceeds it are skipped. Reads fall back
+// to the primary when no replica is available.
+type RoutingQuerier struct {
+	primary  Querier
+	replicas []*replica
+	maxLag   time.Duration
+	ejectFor time.Duration
+	next     atomic.Uint64
+	now      func() time.Time
+}
+
+type replica struct {
+	querier      Querier
+	mu           sync.Mutex
+	ejectedUntil time.Time
+	lag          time.Duration
+}
+
+func NewRoutingQuerier(
+	primary Querier,
+	replicas []Querier,
+	maxLag time.Duration,
+	ejectFor time.Duration,
+) *RoutingQuerier {
+	q := &RoutingQuerier{
+		primary:  primary,
+		maxLag:   maxLag,
+		ejectFor: ejectFor,
+		now:      time.Now,
+	}
+
+	for _, r := range replicas {
+		q.replicas = append(q.replicas, &replica{querier: r})
+	}
+
+	return q
+}
+
+// WithPrimary forces reads made with the context to use the primary. It is
+// needed for SELECT statements that call functions with side effects that
+// RoutingQuerier does not recognize, such as user-defined functions.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey, true)
+}
+
+// wrapped returns the primary, which runs locking reads.
+func (q *RoutingQuerier) wrapped() Querier {
+	return q.primary
+}
+
+func (q *RoutingQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	return q.primary.Begin(ctx)
+}
+
+func (q *RoutingQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return q.primary.Exec(ctx, sql, args...)
+}
+
+func (q *RoutingQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	r := q.route(ctx, sql)
+	if r == nil {
+		return q.primary.Query(ctx, sql, args...)
+	}
+
+	rows, err := r.querier.Query(ctx, sql, args...)
+	if err != nil && ctx.Err() == nil && isConnectionError(err) {
+		// Eject the replica and retry on the primary.
+		q.eject(r)
+		return q.primary.Query(ctx, sql, args...)
+	}
+
+	return rows, err
+}
+
+func (q *RoutingQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := q.Query(ctx, sql, args...)
+	return &queryRow{rows: rows, err: err}
+}
+
+// CheckReplicas measures the lag of every replica, ejecting those that fail.
+// It should be called periodically, such as with MonitorReplicas.
+func (q *RoutingQuerier) CheckReplicas(ctx context.Context) {
+	for _, r := range q.replicas {
+		var lagSecs float64
+		if err := r.querier.QueryRow(ctx, replicaLagSQL).Scan(&lagSecs); err != nil {
+			if ctx.Err() == nil {
+				q.eject(r)
+			}
+			continue
+		}
+
+		r.mu.Lock()
+		r.lag = time.Duration(lagSecs * float64(time.Second))
+		r.mu.Unlock()
+	}
+}
+
+// MonitorReplicas calls CheckReplicas every interval until the context is
+// done.
+func (q *RoutingQuerier) MonitorReplicas(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		q.CheckReplicas(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// route returns the replica for the query or nil for the primary.
+func (q *RoutingQuerier) route(ctx context.Context, sql string) *replica {
+	if len(q.replicas) == 0 || !isRead(sql) {
+		return nil
+	}
+
+	if primary, _ := ctx.Value(primaryKey).(bool); primary {
+		return nil
+	}
+
+	// Round robin over the available replicas.
+	now := q.now()
+	start := q.next.Add(1)
+	for i := range q.replicas {
+		r := q.replicas[(start+uint64(i))%uint64(len(q.replicas))]
+		if r.available(now, q.maxLag) {
+			return r
+		}
+	}
+
+	return nil
+}
+
+func (q *RoutingQuerier) eject(r *replica) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ejectedUntil = q.now().Add(q.ejectFor)
+}
+
+func (r *replica) available(now time.Time, maxLag time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if now.Before(r.ejectedUntil) {
+		return false
+	}
+
+	return maxLag <= 0 || r.lag <= maxLag
+}
+
+// lockingClause matches the row-level locking clauses of a SELECT.
+var lockingClause = regexp.MustCompile(`(?i)\bFOR\s+(UPDATE|NO\s+KEY\s+UPDATE|SHARE|KEY\s+SHARE)\b`)
+
+// writingFunc matches calls to built-in functions that write or take locks.
+var writingFunc = regexp.MustCompile(`(?i)\b(nextval|setval|set_config|pg_notify|pg_(try_)?advisory_\w+|lo_\w+)\s*\(`)
+
+func isRead(sql string) bool {
+	sql = strings.TrimSpace(sql)
+	if len(sql) < 6 || !strings.EqualFold(sql[:6], "SELECT") {
+		return false
+	}
+
+	return !lockingClause.MatchString(sql) && !writingFunc.MatchString(sql)
+}
+
+// isConnectionError reports whether the error came from reaching the server
+// rather than from the query itself.
+func isConnectionError(err error) bool {
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// Connection exceptions and operator intervention, such as a shutdown.
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P")
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) || pgconn.SafeToRetry(err)
+}
+
+// queryRow mirrors the row returned by pgx's QueryRow.
+type queryRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *queryRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+
+		return pgx.ErrNoRows
+	}
+
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	r.rows.Close()
+	return r.rows.Err()
+}
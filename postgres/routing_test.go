@@ -0,0 +1,265 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQuerier struct {
+	err   error
+	calls int
+}
+
+func (q *fakeQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	q.calls++
+	return nil, q.err
+}
+
+func (q *fakeQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	q.calls++
+	return pgconn.CommandTag{}, q.err
+}
+
+func (q *fakeQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	q.calls++
+	return nil, q.err
+}
+
+func (q *fakeQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	q.calls++
+	return &queryRow{err: q.err}
+}
+
+func TestRoutingQuerierRoutesReads(t *testing.T) {
+	t.Parallel()
+
+	primary := &fakeQuerier{}
+	replica1 := &fakeQuerier{}
+	replica2 := &fakeQuerier{}
+	q := NewRoutingQuerier(primary, []Querier{replica1, replica2}, 0, time.Minute)
+
+	ctx := context.Background()
+	for range 4 {
+		_, _ = q.Query(ctx, "  select * FROM values")
+	}
+
+	assert.Equal(t, 0, primary.calls)
+	assert.Equal(t, 2, replica1.calls)
+	assert.Equal(t, 2, replica2.calls)
+}
+
+func TestRoutingQuerierRoutesWrites(t *testing.T) {
+	t.Parallel()
+
+	primary := &fakeQuerier{}
+	replica := &fakeQuerier{}
+	q := NewRoutingQuerier(primary, []Querier{replica}, 0, time.Minute)
+
+	ctx := context.Background()
+	_, _ = q.Exec(ctx, "DELETE FROM values")
+	_, _ = q.Query(ctx, "INSERT INTO values (name, value) VALUES ('a', 'b') RETURNING id")
+	_, _ = q.Query(ctx, "WITH d AS (DELETE FROM values RETURNING id) SELECT * FROM d")
+	_, _ = q.Begin(ctx)
+
+	assert.Equal(t, 4, primary.calls)
+	assert.Equal(t, 0, replica.calls)
+}
+
+func TestIsRead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		sql  string
+		read bool
+	}{
+		{"SELECT * FROM values", true},
+		{"  select 1", true},
+		{"SELECT * FROM values WHERE name = 'format'", true},
+		{"INSERT INTO values (name, value) VALUES ('a', 'b')", false},
+		{"SELECT * FROM values FOR UPDATE", false},
+		{"SELECT * FROM values FOR NO KEY UPDATE SKIP LOCKED", false},
+		{"SELECT * FROM values FOR SHARE", false},
+		{"select * from values for key share nowait", false},
+		{"SELECT nextval('values_id_seq')", false},
+		{"SELECT setval('values_id_seq', 1)", false},
+		{"SELECT pg_advisory_lock(1)", false},
+		{"SELECT pg_try_advisory_xact_lock(1)", false},
+		{"SELECT set_config('app.tenant_id', '1', false)", false},
+		{"SELECT pg_notify('channel', 'payload')", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			assert.Equal(t, tt.read, isRead(tt.sql))
+		})
+	}
+}
+
+func TestRoutingQuerierWithPrimary(t *testing.T) {
+	t.Parallel()
+
+	primary := &fakeQuerier{}
+	replica := &fakeQuerier{}
+	q := NewRoutingQuerier(primary, []Querier{replica}, 0, time.Minute)
+
+	_, _ = q.Query(WithPrimary(context.Background()), "SELECT 1")
+
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 0, replica.calls)
+}
+
+func TestRoutingQuerierNoReplicas(t *testing.T) {
+	t.Parallel()
+
+	primary := &fakeQuerier{}
+	q := NewRoutingQuerier(primary, nil, 0, time.Minute)
+
+	_, _ = q.Query(context.Background(), "SELECT 1")
+
+	assert.Equal(t, 1, primary.calls)
+}
+
+func TestRoutingQuerierEjectsFailingReplica(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	primary := &fakeQuerier{}
+	replica := &fakeQuerier{err: &pgconn.ConnectError{}}
+	q := NewRoutingQuerier(primary, []Querier{replica}, 0, time.Minute)
+	q.now = func() time.Time { return now }
+
+	// The failed read is retried on the primary.
+	ctx := context.Background()
+	_, err := q.Query(ctx, "SELECT 1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, replica.calls)
+
+	// The replica is skipped while ejected.
+	_, _ = q.Query(ctx, "SELECT 1")
+	assert.Equal(t, 2, primary.calls)
+	assert.Equal(t, 1, replica.calls)
+
+	// The replica is used again after the ejection.
+	replica.err = nil
+	now = now.Add(time.Minute)
+	_, _ = q.Query(ctx, "SELECT 1")
+	assert.Equal(t, 2, primary.calls)
+	assert.Equal(t, 2, replica.calls)
+}
+
+func TestRoutingQuerierKeepsReplicaOnQueryError(t *testing.T) {
+	t.Parallel()
+
+	primary := &fakeQuerier{}
+	replica := &fakeQuerier{err: &pgconn.PgError{Code: "42P01"}}
+	q := NewRoutingQuerier(primary, []Querier{replica}, 0, time.Minute)
+
+	ctx := context.Background()
+	_, err := q.Query(ctx, "SELECT * FROM missing")
+	assert.Error(t, err)
+
+	_, _ = q.Query(ctx, "SELECT * FROM missing")
+	assert.Equal(t, 0, primary.calls)
+	assert.Equal(t, 2, replica.calls)
+}
+
+func TestRoutingQuerierCheckReplicasEjects(t *testing.T) {
+	t.Parallel()
+
+	primary := &fakeQuerier{}
+	replica1 := &fakeQuerier{err: errors.New("connection refused")}
+	replica2 := &fakeQuerier{err: &pgconn.ConnectError{}}
+	q := NewRoutingQuerier(primary, []Querier{replica1, replica2}, 0, time.Minute)
+
+	ctx := context.Background()
+	q.CheckReplicas(ctx)
+	_, _ = q.Query(ctx, "SELECT 1")
+
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, replica1.calls)
+	assert.Equal(t, 1, replica2.calls)
+}
+
+func TestRoutingQuerierLagFallback(t *testing.T) {
+	t.Parallel()
+
+	primary := &fakeQuerier{}
+	replica := &fakeQuerier{}
+	q := NewRoutingQuerier(primary, []Querier{replica}, time.Second, time.Minute)
+
+	ctx := context.Background()
+	q.replicas[0].lag = 2 * time.Second
+	_, _ = q.Query(ctx, "SELECT 1")
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 0, replica.calls)
+
+	q.replicas[0].lag = time.Second
+	_, _ = q.Query(ctx, "SELECT 1")
+	assert.Equal(t, 1, primary.calls)
+	assert.Equal(t, 1, replica.calls)
+}
+
+func TestRoutingQuerierDatabases(t *testing.T) {
+	t.Parallel()
+
+	primaryPool := databasePoolForTesting(t)
+	defer primaryPool.Close()
+
+	replicaPool := databasePoolForTesting(t)
+	defer replicaPool.Close()
+
+	ctx := context.Background()
+	_, err := replicaPool.Exec(ctx, "INSERT INTO values (name, value) VALUES ('source', 'replica')")
+	require.NoError(t, err)
+
+	q := NewRoutingQuerier(primaryPool, []Querier{replicaPool}, time.Second, time.Minute)
+	q.CheckReplicas(ctx)
+
+	// Writes go to the primary.
+	_, err = Exec(ctx, q, "INSERT INTO values (name, value) VALUES ('source', 'primary')")
+	require.NoError(t, err)
+
+	// Reads go to the replica unless the primary is forced.
+	row, err := ReadOne[valueRow](ctx, q, "SELECT * FROM values WHERE name = 'source'")
+	require.NoError(t, err)
+	assert.Equal(t, "replica", row.Value)
+
+	row, err = ReadOne[valueRow](WithPrimary(ctx), q, "SELECT * FROM values WHERE name = 'source'")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", row.Value)
+
+	c, err := Count(ctx, q, "SELECT COUNT(*) FROM values")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	// Reads fall back to the primary when the replica is unavailable.
+	config := replicaPool.Config()
+	config.ConnConfig.Database = "missing"
+	missingPool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer missingPool.Close()
+
+	q = NewRoutingQuerier(primaryPool, []Querier{missingPool}, time.Second, time.Minute)
+	row, err = ReadOne[valueRow](ctx, q, "SELECT * FROM values WHERE name = 'source'")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", row.Value)
+}
+
+func TestIsConnectionError(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isConnectionError(&pgconn.ConnectError{}))
+	assert.True(t, isConnectionError(&pgconn.PgError{Code: "08006"}))
+	assert.True(t, isConnectionError(&pgconn.PgError{Code: "57P01"}))
+	assert.False(t, isConnectionError(&pgconn.PgError{Code: "42P01"}))
+	assert.False(t, isConnectionError(errors.New("unable to encode")))
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManualClock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewManualClock(now)
+	assert.Equal(t, now, c.Now())
+
+	// Waiters fire once the clock reaches them.
+	soon := c.After(time.Second)
+	later := c.After(time.Minute)
+	assert.Equal(t, 2, c.Waiters())
+
+	c.Advance(time.Second)
+	assert.Equal(t, now.Add(time.Second), <-soon)
+	assert.Len(t, later, 0)
+	assert.Equal(t, 1, c.Waiters())
+
+	c.Advance(time.Hour)
+	assert.Equal(t, now.Add(time.Hour+time.Second), <-later)
+	assert.Equal(t, 0, c.Waiters())
+
+	// Waiters that are due fire immediately.
+	assert.Equal(t, c.Now(), <-c.After(0))
+}
+
+func TestSystemClock(t *testing.T) {
+	t.Parallel()
+
+	assert.WithinDuration(t, time.Now(), SystemClock.Now(), time.Second)
+	assert.NotZero(t, <-SystemClock.After(time.Millisecond))
+}
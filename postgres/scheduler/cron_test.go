@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduleInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"x * * * *",
+		"* * * foo *",
+		"@never",
+		"CRON_TZ=Nowhere/Special 0 0 * * *",
+	} {
+		_, err := ParseSchedule(expr)
+		assert.ErrorIs(t, err, ErrInvalidSchedule, expr)
+		assert.ErrorIs(t, err, ErrScheduler, expr)
+	}
+}
+
+func TestMustParseSchedule(t *testing.T) {
+	t.Parallel()
+
+	assert.NotPanics(t, func() { MustParseSchedule("* * * * *") })
+	assert.Panics(t, func() { MustParseSchedule("* * * *") })
+}
+
+func TestNext(t *testing.T) {
+	t.Parallel()
+
+	// Monday, 1 January 2024.
+	from := time.Date(2024, 1, 1, 10, 30, 15, 0, time.UTC)
+
+	for expr, expected := range map[string]time.Time{
+		"* * * * *":               time.Date(2024, 1, 1, 10, 31, 0, 0, time.UTC),
+		"* * * * * *":             time.Date(2024, 1, 1, 10, 30, 16, 0, time.UTC),
+		"*/20 * * * * *":          time.Date(2024, 1, 1, 10, 30, 20, 0, time.UTC),
+		"30 9 * * *":              time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC),
+		"0 12 * * 1-5":            time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		"0 0 * * sat,sun":         time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC),
+		"0 0 * * 7":               time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		"0 0 1 FEB ?":             time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"0 0 29 2 *":              time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		"0 0 31 * *":              time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		"0 0 13 * 5":              time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC),
+		"15,45 10-11 * * *":       time.Date(2024, 1, 1, 10, 45, 0, 0, time.UTC),
+		"0 10/6 * * *":            time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC),
+		"@hourly":                 time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC),
+		"@weekly":                 time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC),
+		"@yearly":                 time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		"TZ=Asia/Tokyo 0 9 * * *": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	} {
+		s, err := ParseSchedule(expr)
+		require.NoError(t, err, expr)
+		assert.True(t, expected.Equal(s.Next(from)), "%s: %s", expr, s.Next(from))
+	}
+}
+
+func TestNextNever(t *testing.T) {
+	t.Parallel()
+
+	s := MustParseSchedule("0 0 30 2 *")
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestNextTimeZone(t *testing.T) {
+	t.Parallel()
+
+	loc, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	s := MustParseSchedule("CRON_TZ=America/New_York 30 1 * * *")
+	assert.Equal(t, loc, s.Location())
+
+	// Clocks fall back at 2:00 on 3 November 2024, and the daily task runs once.
+	next := s.Next(time.Date(2024, 11, 3, 0, 0, 0, 0, loc))
+	assert.True(t, time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC).Equal(next), next)
+
+	next = s.Next(next)
+	assert.True(t, time.Date(2024, 11, 4, 6, 30, 0, 0, time.UTC).Equal(next), next)
+
+	// Hourly tasks run every hour.
+	s = MustParseSchedule("CRON_TZ=America/New_York 0 * * * *")
+	next = s.Next(time.Date(2024, 11, 3, 5, 30, 0, 0, time.UTC))
+	assert.True(t, time.Date(2024, 11, 3, 6, 0, 0, 0, time.UTC).Equal(next), next)
+
+	// Clocks spring forward at 2:00 on 10 March 2024, so 2:30 does not occur.
+	s = MustParseSchedule("CRON_TZ=America/New_York 30 2 * * *")
+	next = s.Next(time.Date(2024, 3, 9, 12, 0, 0, 0, loc))
+	assert.True(t, time.Date(2024, 3, 11, 2, 30, 0, 0, loc).Equal(next), next)
+}
@@ -0,0 +1,260 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	common "github.com/jeremybower/go-common"
+	"github.com/jeremybower/go-common/postgres"
+)
+
+var ErrScheduler = errors.New("postgres scheduler")
+var ErrDuplicateTask = fmt.Errorf("%w: duplicate task", ErrScheduler)
+var ErrUnknownTask = fmt.Errorf("%w: unknown task", ErrScheduler)
+var ErrTask = fmt.Errorf("%w: task failed", ErrScheduler)
+
+// Migration creates the scheduler_runs table. Each task runs at most once for
+// each scheduled time.
+const Migration = `
+CREATE TABLE IF NOT EXISTS scheduler_runs (
+	id BIGSERIAL PRIMARY KEY,
+	task TEXT NOT NULL,
+	scheduled_at TIMESTAMPTZ NOT NULL,
+	started_at TIMESTAMPTZ NOT NULL,
+	finished_at TIMESTAMPTZ NOT NULL,
+	error TEXT,
+	UNIQUE (task, scheduled_at)
+);
+`
+
+type Run struct {
+	ID          int64     `db:"id"`
+	Task        string    `db:"task"`
+	ScheduledAt time.Time `db:"scheduled_at"`
+	StartedAt   time.Time `db:"started_at"`
+	FinishedAt  time.Time `db:"finished_at"`
+	Error       *string   `db:"error"`
+}
+
+type Func func(ctx context.Context) error
+
+type task struct {
+	name     string
+	schedule *Schedule
+	fn       Func
+}
+
+// Scheduler runs tasks on cron schedules. Every replica can run a scheduler
+// with the same tasks: each run holds a Postgres advisory lock and is recorded
+// in the scheduler_runs table, so only one replica runs a task at a time and
+// each scheduled time runs once.
+type Scheduler struct {
+	querier postgres.Querier
+	clock   Clock
+	tasks   []*task
+}
+
+func NewScheduler(querier postgres.Querier, clock Clock) *Scheduler {
+	return &Scheduler{querier: querier, clock: clock}
+}
+
+// Register adds a task that runs on the cron expression, which is parsed by
+// ParseSchedule. Tasks must be registered before Run is called.
+func (s *Scheduler) Register(name string, expr string, fn Func) error {
+	// Check for duplicates.
+	if s.task(name) != nil {
+		return fmt.Errorf("%w: %s", ErrDuplicateTask, name)
+	}
+
+	// Parse the schedule.
+	schedule, err := ParseSchedule(expr)
+	if err != nil {
+		return err
+	}
+
+	// Success.
+	s.tasks = append(s.tasks, &task{name: name, schedule: schedule, fn: fn})
+	return nil
+}
+
+func (s *Scheduler) task(name string) *task {
+	for _, t := range s.tasks {
+		if t.name == name {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// Next returns the next time that the task is scheduled to run after t.
+func (s *Scheduler) Next(name string, t time.Time) (time.Time, error) {
+	task := s.task(name)
+	if task == nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrUnknownTask, name)
+	}
+
+	// Success.
+	return task.schedule.Next(t), nil
+}
+
+// RunTask runs the task for the scheduled time unless another replica holds
+// its lock or it has already run for that time, and reports whether it ran.
+// The run is recorded even when the task fails or panics, and the failure is
+// returned wrapped in ErrTask.
+//
+// The lock is held by a transaction that stays open while the task runs, so
+// tasks should finish within the server's idle_in_transaction_session_timeout.
+func (s *Scheduler) RunTask(ctx context.Context, name string, scheduledAt time.Time) (bool, error) {
+	task := s.task(name)
+	if task == nil {
+		return false, fmt.Errorf("%w: %s", ErrUnknownTask, name)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	// Record the run even when the task is cancelled.
+	dbCtx := context.WithoutCancel(ctx)
+
+	var ran bool
+	var taskErr error
+	err := postgres.WithTx(dbCtx, s.querier, func(tx pgx.Tx) error {
+		// Lock the task.
+		locked, err := postgres.ReadOne[bool](dbCtx, tx,
+			"SELECT pg_try_advisory_xact_lock(hashtextextended('scheduler:' || $1, 0))",
+			name,
+		)
+		if err != nil {
+			return err
+		}
+
+		if !*locked {
+			return nil
+		}
+
+		// Skip the run when it has already happened.
+		exists, err := postgres.ReadOne[bool](dbCtx, tx,
+			"SELECT EXISTS (SELECT 1 FROM scheduler_runs WHERE task = $1 AND scheduled_at = $2)",
+			name, scheduledAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		if *exists {
+			return nil
+		}
+
+		// Run the task.
+		startedAt := s.clock.Now()
+		taskErr = call(ctx, task.fn)
+		ran = true
+
+		// Record the run.
+		var lastError *string
+		if taskErr != nil {
+			msg := taskErr.Error()
+			lastError = &msg
+			taskErr = fmt.Errorf("%w: %s: %w", ErrTask, name, taskErr)
+		}
+
+		_, err = postgres.Exec(dbCtx, tx,
+			"INSERT INTO scheduler_runs (task, scheduled_at, started_at, finished_at, error) VALUES ($1, $2, $3, $4, $5)",
+			name, scheduledAt, startedAt, s.clock.Now(), lastError,
+		)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	// Success.
+	return ran, taskErr
+}
+
+func call(ctx context.Context, fn Func) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return fn(ctx)
+}
+
+// Run runs the tasks on their schedules until the context is cancelled, and
+// then waits for the running tasks to return. Times that are missed, such as
+// while a previous run of the task is still running, are skipped. Failures
+// are logged with the context's logger, when set.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	// Schedule the tasks.
+	now := s.clock.Now()
+	next := make([]time.Time, len(s.tasks))
+	for i, t := range s.tasks {
+		next[i] = t.schedule.Next(now)
+	}
+
+	for {
+		// Wait for the earliest run.
+		var at time.Time
+		for _, n := range next {
+			if !n.IsZero() && (at.IsZero() || n.Before(at)) {
+				at = n
+			}
+		}
+
+		var wait <-chan time.Time
+		if !at.IsZero() {
+			wait = s.clock.After(at.Sub(s.clock.Now()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-wait:
+		}
+
+		// Start the tasks that are due.
+		now = s.clock.Now()
+		for i, t := range s.tasks {
+			if next[i].IsZero() || next[i].After(now) {
+				continue
+			}
+
+			scheduledAt := next[i]
+			next[i] = t.schedule.Next(now)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				if _, err := s.RunTask(ctx, t.name, scheduledAt); err != nil && ctx.Err() == nil {
+					if logger, loggerErr := common.Logger(ctx); loggerErr == nil {
+						logger.ErrorContext(ctx, "failed to run scheduled task", "task", t.name, "error", err)
+					}
+				}
+			}()
+		}
+	}
+}
+
+// History returns the task's most recent runs, newest first.
+func (s *Scheduler) History(ctx context.Context, name string, limit int) ([]*Run, error) {
+	return postgres.ReadMany[Run](ctx, s.querier, `
+		SELECT id, task, scheduled_at, started_at, finished_at, error
+		FROM scheduler_runs
+		WHERE task = $1
+		ORDER BY scheduled_at DESC
+		LIMIT $2`,
+		name, limit,
+	)
+}
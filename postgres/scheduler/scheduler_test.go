@@ -0,0 +1,200 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jeremybower/go-common/internal/pgtest"
+	"github.com/jeremybower/go-common/postgres"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func databasePoolForTesting(t *testing.T) *pgxpool.Pool {
+	return pgtest.Pool(t, Migration)
+}
+
+func noop(ctx context.Context) error {
+	return nil
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	s := NewScheduler(nil, SystemClock)
+	require.NoError(t, s.Register("task", "0 * * * *", noop))
+
+	err := s.Register("task", "0 * * * *", noop)
+	assert.ErrorIs(t, err, ErrDuplicateTask)
+
+	err = s.Register("other", "0 * * *", noop)
+	assert.ErrorIs(t, err, ErrInvalidSchedule)
+
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	next, err := s.Next("task", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), next)
+
+	_, err = s.Next("other", now)
+	assert.ErrorIs(t, err, ErrUnknownTask)
+
+	_, err = s.RunTask(context.Background(), "other", now)
+	assert.ErrorIs(t, err, ErrUnknownTask)
+}
+
+func TestRunTask(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := NewScheduler(dbPool, clock)
+
+	var runs atomic.Int32
+	errFailed := errors.New("failed")
+	require.NoError(t, s.Register("task", "@hourly", func(ctx context.Context) error {
+		clock.Advance(time.Second)
+		if runs.Add(1) == 2 {
+			return errFailed
+		}
+
+		return nil
+	}))
+
+	first := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+
+	// The task runs once for each scheduled time.
+	ran, err := s.RunTask(ctx, "task", first)
+	require.NoError(t, err)
+	assert.True(t, ran)
+
+	ran, err = s.RunTask(ctx, "task", first)
+	require.NoError(t, err)
+	assert.False(t, ran)
+
+	// Failures are recorded.
+	ran, err = s.RunTask(ctx, "task", second)
+	assert.ErrorIs(t, err, ErrTask)
+	assert.ErrorIs(t, err, errFailed)
+	assert.True(t, ran)
+	assert.Equal(t, int32(2), runs.Load())
+
+	history, err := s.History(ctx, "task", 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+
+	assert.True(t, second.Equal(history[0].ScheduledAt))
+	assert.Equal(t, time.Second, history[0].FinishedAt.Sub(history[0].StartedAt))
+	require.NotNil(t, history[0].Error)
+	assert.Equal(t, "failed", *history[0].Error)
+
+	assert.True(t, first.Equal(history[1].ScheduledAt))
+	assert.Nil(t, history[1].Error)
+}
+
+func TestRunTaskPanic(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	s := NewScheduler(dbPool, SystemClock)
+	require.NoError(t, s.Register("task", "@hourly", func(ctx context.Context) error {
+		panic("boom")
+	}))
+
+	ran, err := s.RunTask(ctx, "task", time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrTask)
+	assert.True(t, ran)
+
+	history, err := s.History(ctx, "task", 1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	require.NotNil(t, history[0].Error)
+	assert.Equal(t, "panic: boom", *history[0].Error)
+}
+
+func TestRunTaskLocked(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	s := NewScheduler(dbPool, SystemClock)
+	require.NoError(t, s.Register("task", "@hourly", func(ctx context.Context) error {
+		t.Fatal("task should not run")
+		return nil
+	}))
+
+	// Another replica is running the task.
+	err := postgres.WithTx(ctx, dbPool, func(tx pgx.Tx) error {
+		if _, err := postgres.Exec(ctx, tx, "SELECT pg_advisory_xact_lock(hashtextextended('scheduler:task', 0))"); err != nil {
+			return err
+		}
+
+		ran, err := s.RunTask(ctx, "task", time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC))
+		assert.False(t, ran)
+		return err
+	})
+	require.NoError(t, err)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	clock := NewManualClock(time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC))
+
+	// Two replicas run the same task.
+	var mu sync.Mutex
+	var scheduled []time.Time
+	task := func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		scheduled = append(scheduled, clock.Now())
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	for range 2 {
+		s := NewScheduler(dbPool, clock)
+		require.NoError(t, s.Register("task", "@hourly", task))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.ErrorIs(t, s.Run(ctx), context.Canceled)
+		}()
+	}
+
+	// Each hour runs once.
+	for hour := 1; hour <= 3; hour++ {
+		require.Eventually(t, func() bool { return clock.Waiters() == 2 }, 5*time.Second, 10*time.Millisecond)
+		clock.Advance(time.Hour)
+
+		require.Eventually(t, func() bool {
+			history, err := NewScheduler(dbPool, clock).History(ctx, "task", 10)
+			return err == nil && len(history) == hour
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	cancel()
+	wg.Wait()
+
+	assert.Len(t, scheduled, 3)
+}
@@ -0,0 +1,16 @@
+package postgres
+
+// Search is user input for full-text search, which the ts template
+// functions pass to websearch_to_tsquery as an argument. The input may use
+// the web search syntax, such as quoted phrases, OR and -, and invalid
+// syntax is ignored rather than failing the query.
+type Search struct {
+	// Config is the text search configuration, such as "english". The
+	// server's default_text_search_config is used when it is empty.
+	Config string
+	Query  string
+}
+
+func NewSearch(config string, query string) Search {
+	return Search{Config: config, Query: query}
+}
@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type searchRow struct {
+	ID       int64   `db:"id"`
+	Rank     float32 `db:"rank"`
+	Headline string  `db:"headline"`
+}
+
+func TestSearchList(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := Exec(ctx, dbPool, `INSERT INTO values (name, value) VALUES
+		('a', 'The fat cat sat on the mat'),
+		('b', 'A cat and a fat rat'),
+		('c', 'Dogs chase cats'),
+		('d', 'Nothing to see here')`)
+	require.NoError(t, err)
+
+	templ := MustParse(`
+		SELECT {{ if counting }} COUNT(*) {{ else }}
+			id,
+			{{ tsRank "to_tsvector('english', value)" .Search }} AS rank,
+			{{ tsHeadline "value" .Search "StartSel=[, StopSel=]" }} AS headline
+		{{ end }}
+		FROM values
+		WHERE {{ tsMatch "to_tsvector('english', value)" .Search }}
+		{{ if not counting }} ORDER BY rank DESC, id LIMIT {{ pageSize }} OFFSET {{ firstItemIndex }} {{ end }}`)
+
+	// Quotes and operators in the input are web search syntax, not errors.
+	data := map[string]any{"Search": NewSearch("english", `cat -"fat rat" 'or`)}
+	page, err := ListT[searchRow](ctx, dbPool, templ, data, 0, 1, 1, 10, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.TotalItems)
+	require.Len(t, page.Items, 1)
+	assert.Contains(t, page.Items[0].Headline, "[cat]")
+	assert.Greater(t, page.Items[0].Rank, float32(0))
+}
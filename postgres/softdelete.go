@@ -0,0 +1,46 @@
+package postgres
+
+import "context"
+
+// DeletedAtColumn is the column that marks a row as soft deleted.
+const DeletedAtColumn = "deleted_at"
+
+const includeDeletedKey = contextKey("includeDeleted")
+
+// IncludeDeleted makes the notDeleted template function match soft deleted
+// rows too, such as for an admin view or before a Restore.
+func IncludeDeleted(ctx context.Context) context.Context {
+	return context.WithValue(ctx, includeDeletedKey, true)
+}
+
+func DeletedIncluded(ctx context.Context) bool {
+	included, _ := ctx.Value(includeDeletedKey).(bool)
+	return included
+}
+
+// DeleteWhere soft deletes the rows of the table that match the condition
+// by setting DeletedAtColumn, returning how many rows were deleted. Rows that
+// are already deleted keep their original deletion time.
+func DeleteWhere(
+	ctx context.Context,
+	querier Querier,
+	table string,
+	where string,
+	args ...any,
+) (int64, error) {
+	sql := "UPDATE " + table + " SET " + DeletedAtColumn + " = now() WHERE " + DeletedAtColumn + " IS NULL AND (" + where + ")"
+	return Exec(ctx, querier, sql, args...)
+}
+
+// Restore undoes DeleteWhere for the rows of the table that match the
+// condition, returning how many rows were restored.
+func Restore(
+	ctx context.Context,
+	querier Querier,
+	table string,
+	where string,
+	args ...any,
+) (int64, error) {
+	sql := "UPDATE " + table + " SET " + DeletedAtColumn + " = NULL WHERE " + DeletedAtColumn + " IS NOT NULL AND (" + where + ")"
+	return Exec(ctx, querier, sql, args...)
+}
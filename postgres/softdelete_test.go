@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeletedIncluded(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	assert.False(t, DeletedIncluded(ctx))
+	assert.True(t, DeletedIncluded(IncludeDeleted(ctx)))
+}
+
+func TestSoftDelete(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := Exec(ctx, dbPool, "ALTER TABLE values ADD COLUMN deleted_at TIMESTAMPTZ")
+	require.NoError(t, err)
+
+	_, err = Exec(ctx, dbPool, "INSERT INTO values (name, value) VALUES ('a', 'kept'), ('b', 'deleted')")
+	require.NoError(t, err)
+
+	count := MustParse(`SELECT COUNT(*) FROM values WHERE {{ notDeleted }}`)
+
+	// Deleted rows are excluded.
+	c, err := DeleteWhere(ctx, dbPool, "values", "name = $1", "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	c, err = DeleteWhere(ctx, dbPool, "values", "name = $1", "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), c)
+
+	c, err = CountT(ctx, dbPool, count, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	c, err = CountT(IncludeDeleted(ctx), dbPool, count, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+
+	// Restored rows are included again.
+	c, err = Restore(ctx, dbPool, "values", "name = $1", "b")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	c, err = CountT(ctx, dbPool, count, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+}
@@ -0,0 +1,216 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrStatement = errors.New("postgres statement")
+var ErrStatementNameRequired = fmt.Errorf("%w: name is required", ErrStatement)
+var ErrStatementNameConflict = fmt.Errorf("%w: name already registered with different sql", ErrStatement)
+
+type contextKey string
+
+const statementsKey = contextKey("statements")
+
+type StatementStats struct {
+	Prepared int
+	Hits     int64
+	Misses   int64
+}
+
+// Statements is a set of named SQL statements that are prepared on each
+// connection, either eagerly with AfterConnect or lazily on first use.
+type Statements struct {
+	mu     sync.RWMutex
+	names  map[string]string // sql -> name
+	sqls   map[string]string // name -> sql
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func NewStatements() *Statements {
+	return &Statements{
+		names: map[string]string{},
+		sqls:  map[string]string{},
+	}
+}
+
+func (s *Statements) Add(name string, sql string) error {
+	if name == "" {
+		return ErrStatementNameRequired
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.sqls[name]; ok {
+		if existing != sql {
+			return fmt.Errorf("%w: %s", ErrStatementNameConflict, name)
+		}
+
+		return nil
+	}
+
+	// The same shape may be rendered by more than one sample, so the first
+	// name registered for it wins.
+	if _, ok := s.names[sql]; ok {
+		return nil
+	}
+
+	s.names[sql] = name
+	s.sqls[name] = sql
+	return nil
+}
+
+// AddT renders the template once for each sample and registers every
+// resulting shape. The first shape is named after the template and the
+// remaining shapes are suffixed with their index. The shape used by CountT
+// and ListT to count is registered too, with a _count suffix, when the
+// template can be counted.
+func (s *Statements) AddT(templ *Template, samples ...map[string]any) error {
+	if templ.Name() == "" {
+		return ErrStatementNameRequired
+	}
+
+	for i, data := range samples {
+		name := templ.Name()
+		if i > 0 {
+			name = fmt.Sprintf("%s_%d", name, i)
+		}
+
+		sql, err := templ.executeShape(data, false)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Add(name, sql); err != nil {
+			return err
+		}
+
+		// Templates that paginate without checking counting cannot count.
+		sql, err = templ.executeShape(data, true)
+		if errors.Is(err, ErrTemplateFuncNotAvail) {
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if err := s.Add(name+"_count", sql); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AfterConnect prepares every registered statement on the connection. It is
+// intended to be assigned to pgxpool.Config.AfterConnect.
+func (s *Statements) AfterConnect(ctx context.Context, conn *pgx.Conn) error {
+	s.mu.RLock()
+	sqls := make(map[string]string, len(s.sqls))
+	for name, sql := range s.sqls {
+		sqls[name] = sql
+	}
+	s.mu.RUnlock()
+
+	for name, sql := range sqls {
+		if _, err := conn.Prepare(ctx, name, sql); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Statements) Stats() StatementStats {
+	s.mu.RLock()
+	prepared := len(s.sqls)
+	s.mu.RUnlock()
+
+	return StatementStats{
+		Prepared: prepared,
+		Hits:     s.hits.Load(),
+		Misses:   s.misses.Load(),
+	}
+}
+
+func (s *Statements) lookup(sql string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	name, ok := s.names[sql]
+	return name, ok
+}
+
+func WithStatements(ctx context.Context, statements *Statements) context.Context {
+	return context.WithValue(ctx, statementsKey, statements)
+}
+
+// prepared returns a querier and statement name to execute in place of the
+// rendered SQL when the template is named, its shape has been registered and
+// the querier can execute prepared statements, which counts as a hit.
+// Otherwise, the querier and SQL are returned unchanged, which counts as a
+// miss. The release function must always be called.
+func prepared(
+	ctx context.Context,
+	querier Querier,
+	templ *Template,
+	sql string,
+) (Querier, string, func(), error) {
+	release := func() {}
+
+	statements, ok := ctx.Value(statementsKey).(*Statements)
+	if !ok || templ.Name() == "" {
+		return querier, sql, release, nil
+	}
+
+	name, ok := statements.lookup(sql)
+	if !ok {
+		statements.misses.Add(1)
+		return querier, sql, release, nil
+	}
+
+	// Find the connection that will execute the statement. A pool connection
+	// is acquired here in place of the one the pool would acquire to execute
+	// the statement, so no extra connection is used.
+	var conn *pgx.Conn
+	switch q := querier.(type) {
+	case *pgx.Conn:
+		conn = q
+	case interface{ Conn() *pgx.Conn }:
+		conn = q.Conn()
+	case interface {
+		Acquire(ctx context.Context) (*pgxpool.Conn, error)
+	}:
+		poolConn, err := q.Acquire(ctx)
+		if err != nil {
+			return nil, "", release, err
+		}
+
+		querier = poolConn
+		conn = poolConn.Conn()
+		release = poolConn.Release
+	default:
+		statements.misses.Add(1)
+		return querier, sql, release, nil
+	}
+
+	// The connection remembers its prepared statements, so this only reaches
+	// the server the first time the statement is used on the connection,
+	// unless AfterConnect already prepared it.
+	if _, err := conn.Prepare(ctx, name, sql); err != nil {
+		release()
+		return nil, "", func() {}, err
+	}
+
+	// Success.
+	statements.hits.Add(1)
+	return querier, name, release, nil
+}
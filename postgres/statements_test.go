@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementsAdd(t *testing.T) {
+	t.Parallel()
+
+	s := NewStatements()
+	assert.NoError(t, s.Add("a", "SELECT 1"))
+	assert.NoError(t, s.Add("a", "SELECT 1"))
+	assert.NoError(t, s.Add("b", "SELECT 1"))
+	assert.ErrorIs(t, s.Add("a", "SELECT 2"), ErrStatementNameConflict)
+	assert.ErrorIs(t, s.Add("", "SELECT 2"), ErrStatementNameRequired)
+	assert.Equal(t, StatementStats{Prepared: 1}, s.Stats())
+}
+
+func TestStatementsAddT(t *testing.T) {
+	t.Parallel()
+
+	templ := MustParseNamed("values_by_id", `SELECT * FROM values WHERE id = {{ arg .ID }}{{ if .Name }} AND name = {{ arg .Name }}{{ end }}`)
+
+	s := NewStatements()
+	require.NoError(t, s.AddT(templ, map[string]any{"ID": 1}, map[string]any{"ID": 1, "Name": "name"}))
+	assert.Equal(t, 2, s.Stats().Prepared)
+
+	name, ok := s.lookup("SELECT * FROM values WHERE id = $1")
+	assert.True(t, ok)
+	assert.Equal(t, "values_by_id", name)
+
+	name, ok = s.lookup("SELECT * FROM values WHERE id = $1 AND name = $2")
+	assert.True(t, ok)
+	assert.Equal(t, "values_by_id_1", name)
+
+	_, ok = s.lookup("SELECT * FROM values")
+	assert.False(t, ok)
+
+	assert.Equal(t, StatementStats{Prepared: 2}, s.Stats())
+	assert.ErrorIs(t, s.AddT(MustParse("SELECT 1")), ErrStatementNameRequired)
+
+	// The tenant is a placeholder when the shape is rendered.
+	require.NoError(t, s.AddT(MustParseNamed("notes", "SELECT * FROM notes WHERE tenant_id = {{ tenant }}"), nil))
+	name, ok = s.lookup("SELECT * FROM notes WHERE tenant_id = $1")
+	assert.True(t, ok)
+	assert.Equal(t, "notes", name)
+
+	// The counting shape is registered for templates that can count.
+	list := MustParseNamed("values_page", `SELECT {{ if counting }}COUNT(*){{ else }}*{{ end }} FROM values{{ if not counting }} LIMIT {{ pageSize }} OFFSET {{ firstItemIndex }}{{ end }}`)
+	require.NoError(t, s.AddT(list, nil))
+
+	name, ok = s.lookup("SELECT * FROM values LIMIT $1 OFFSET $2")
+	assert.True(t, ok)
+	assert.Equal(t, "values_page", name)
+
+	name, ok = s.lookup("SELECT COUNT(*) FROM values")
+	assert.True(t, ok)
+	assert.Equal(t, "values_page_count", name)
+
+	// Only the listing shape is registered for templates that cannot count.
+	require.NoError(t, s.AddT(MustParseNamed("unguarded", "SELECT * FROM values LIMIT {{ pageSize }}"), nil))
+	assert.Equal(t, 6, s.Stats().Prepared)
+}
+
+func TestStatementsUnpreparable(t *testing.T) {
+	t.Parallel()
+
+	templ := MustParseNamed("values_by_id", "SELECT * FROM values WHERE id = {{ arg .ID }}")
+	data := map[string]any{"ID": 1}
+
+	s := NewStatements()
+	require.NoError(t, s.AddT(templ, data))
+	ctx := WithStatements(context.Background(), s)
+
+	// The shape is registered, but the querier cannot prepare statements.
+	querier := &fakeQuerier{}
+	q, sql, release, err := prepared(ctx, querier, templ, "SELECT * FROM values WHERE id = $1")
+	require.NoError(t, err)
+	defer release()
+
+	assert.Equal(t, querier, q)
+	assert.Equal(t, "SELECT * FROM values WHERE id = $1", sql)
+	assert.Equal(t, StatementStats{Prepared: 1, Misses: 1}, s.Stats())
+}
+
+func TestStatementsLazy(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	var id int64
+	ctx := context.Background()
+	err := dbPool.QueryRow(ctx, "INSERT INTO values (name, value) VALUES ('name', 'value') RETURNING id;").Scan(&id)
+	require.NoError(t, err)
+
+	templ := MustParseNamed("values_by_id", "SELECT * FROM values WHERE id = {{ arg .ID }}")
+	data := map[string]any{"ID": id}
+
+	s := NewStatements()
+	require.NoError(t, s.AddT(templ, data))
+	ctx = WithStatements(ctx, s)
+
+	row, err := ReadOneT[valueRow](ctx, dbPool, templ, data)
+	require.NoError(t, err)
+	assert.Equal(t, id, row.ID)
+
+	rows, err := ReadManyT[valueRow](ctx, dbPool, templ, data)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+
+	tx, err := dbPool.Begin(ctx)
+	require.NoError(t, err)
+	defer tx.Rollback(ctx)
+
+	row, err = ReadOneT[valueRow](ctx, tx, templ, data)
+	require.NoError(t, err)
+	assert.Equal(t, id, row.ID)
+
+	c, err := ExecT(ctx, tx, MustParseNamed("delete_value", "DELETE FROM values WHERE id = {{ arg .ID }}"), data)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	assert.Equal(t, StatementStats{Prepared: 1, Hits: 3, Misses: 1}, s.Stats())
+}
+
+func TestStatementsListT(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	_, err := dbPool.Exec(ctx, "INSERT INTO values (name, value) VALUES ('a', 'a'), ('b', 'b'), ('c', 'c')")
+	require.NoError(t, err)
+
+	templ := MustParseNamed("values_page", `SELECT {{ if counting }}COUNT(*){{ else }}*{{ end }} FROM values{{ if not counting }} LIMIT {{ pageSize }} OFFSET {{ firstItemIndex }}{{ end }}`)
+
+	s := NewStatements()
+	require.NoError(t, s.AddT(templ, nil))
+	ctx = WithStatements(ctx, s)
+
+	page, err := ListT[valueRow](ctx, dbPool, templ, nil, 0, 2, 1, 10, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), page.TotalItems)
+	assert.Len(t, page.Items, 2)
+
+	// Both the count and the page use prepared statements.
+	assert.Equal(t, StatementStats{Prepared: 2, Hits: 2}, s.Stats())
+}
+
+func TestStatementsAfterConnect(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	var id int64
+	ctx := context.Background()
+	err := dbPool.QueryRow(ctx, "INSERT INTO values (name, value) VALUES ('name', 'value') RETURNING id;").Scan(&id)
+	require.NoError(t, err)
+
+	templ := MustParseNamed("values_by_id", "SELECT * FROM values WHERE id = {{ arg .ID }}")
+	data := map[string]any{"ID": id}
+
+	s := NewStatements()
+	require.NoError(t, s.AddT(templ, data))
+
+	config := dbPool.Config()
+	config.AfterConnect = s.AfterConnect
+	preparedPool, err := pgxpool.NewWithConfig(ctx, config)
+	require.NoError(t, err)
+	defer preparedPool.Close()
+
+	ctx = WithStatements(ctx, s)
+	row, err := ReadOneT[valueRow](ctx, preparedPool, templ, data)
+	require.NoError(t, err)
+	assert.Equal(t, id, row.ID)
+	assert.Equal(t, StatementStats{Prepared: 1, Hits: 1}, s.Stats())
+}
@@ -1,6 +1,8 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
@@ -14,18 +16,25 @@ var ErrJoinNotStarted = fmt.Errorf("%w: join not started", ErrTemplate)
 var ErrJoinNotEnded = fmt.Errorf("%w: join started but not ended", ErrTemplate)
 
 type Template struct {
-	t *template.Template
+	name string
+	t    *template.Template
 }
 
 func Parse(text string) (*Template, error) {
+	return ParseNamed("", text)
+}
+
+func ParseNamed(name string, text string) (*Template, error) {
 	// Generate stub functions for parsing. These will be replaced with
 	// contextualized versions when the template is executed.
 	funcs := templateFuncs(
 		nil,   // args
 		false, // counting
 		nil,   // firstItemIndex
+		false, // includeDeleted
 		nil,   // joinFrames
 		nil,   // pageSize
+		nil,   // tenant
 	)
 
 	// Parse the template.
@@ -35,11 +44,15 @@ func Parse(text string) (*Template, error) {
 	}
 
 	// Success.
-	return &Template{t}, nil
+	return &Template{name: name, t: t}, nil
 }
 
 func MustParse(text string) *Template {
-	t, err := Parse(text)
+	return MustParseNamed("", text)
+}
+
+func MustParseNamed(name string, text string) *Template {
+	t, err := ParseNamed(name, text)
 	if err != nil {
 		panic(err)
 	}
@@ -47,29 +60,77 @@ func MustParse(text string) *Template {
 	return t
 }
 
+func (t *Template) Name() string {
+	return t.name
+}
+
 func (t *Template) Execute(data interface{}) (string, []any, error) {
-	return t.execute(data, false, nil, nil)
+	return t.ExecuteContext(context.Background(), data)
 }
 
 func (t *Template) ExecuteCount(data interface{}) (string, []any, error) {
-	return t.execute(data, true, nil, nil)
+	return t.ExecuteCountContext(context.Background(), data)
 }
 
 func (t *Template) ExecuteList(data interface{}, firstItemIndex int64, pageSize int64) (string, []any, error) {
-	return t.execute(data, false, &firstItemIndex, &pageSize)
+	return t.ExecuteListContext(context.Background(), data, firstItemIndex, pageSize)
+}
+
+// ExecuteContext is like Execute but makes the context's tenant available to
+// the tenant function and includes deleted rows in the notDeleted function
+// when IncludeDeleted was called.
+func (t *Template) ExecuteContext(ctx context.Context, data interface{}) (string, []any, error) {
+	return t.execute(ctx, data, false, nil, nil, false)
+}
+
+func (t *Template) ExecuteCountContext(ctx context.Context, data interface{}) (string, []any, error) {
+	return t.execute(ctx, data, true, nil, nil, false)
+}
+
+func (t *Template) ExecuteListContext(
+	ctx context.Context,
+	data interface{},
+	firstItemIndex int64,
+	pageSize int64,
+) (string, []any, error) {
+	return t.execute(ctx, data, false, &firstItemIndex, &pageSize, false)
+}
+
+// executeShape renders the SQL without the context, writing placeholders for
+// the tenant and pagination. The SQL has the same shape as when they are set,
+// so it can be prepared ahead of time, but the args must not be used.
+func (t *Template) executeShape(data interface{}, counting bool) (string, error) {
+	sql, _, err := t.execute(context.Background(), data, counting, nil, nil, true)
+	return sql, err
 }
 
 func (t *Template) execute(
+	ctx context.Context,
 	data interface{},
 	counting bool,
 	firstItemIndex *int64,
 	pageSize *int64,
+	shape bool,
 ) (string, []any, error) {
 	localTemplate, err := t.t.Clone()
 	if err != nil {
 		return "", nil, err
 	}
 
+	var tenant *string
+	if tenantID, ok := Tenant(ctx); ok {
+		tenant = &tenantID
+	}
+
+	// Only the placeholders are needed for the shape, so any value will do.
+	if shape {
+		tenant = new(string)
+		if !counting {
+			firstItemIndex = new(int64)
+			pageSize = new(int64)
+		}
+	}
+
 	var args []any
 	var buf strings.Builder
 	var joinFrames []joinFrame
@@ -77,8 +138,10 @@ func (t *Template) execute(
 		&args,
 		counting,
 		firstItemIndex,
+		DeletedIncluded(ctx),
 		&joinFrames,
 		pageSize,
+		tenant,
 	)).Execute(&buf, data); err != nil {
 		return "", nil, err
 	}
@@ -94,17 +157,32 @@ func templateFuncs(
 	args *[]any,
 	counting bool,
 	firstItemIndex *int64,
+	includeDeleted bool,
 	joinFrames *[]joinFrame,
 	pageSize *int64,
+	tenant *string,
 ) template.FuncMap {
 	return template.FuncMap{
-		"arg":            templateFuncArg(args),
-		"counting":       templateFuncCounting(counting),
-		"endJoin":        templateFuncEndJoin(joinFrames),
-		"firstItemIndex": templateFuncFirstItemIndex(firstItemIndex, args),
-		"join":           templateFuncJoin(joinFrames),
-		"pageSize":       templateFuncPageSize(pageSize, args),
-		"sep":            templateFuncSep(joinFrames),
+		"arg":              templateFuncArg(args),
+		"counting":         templateFuncCounting(counting),
+		"endJoin":          templateFuncEndJoin(joinFrames),
+		"firstItemIndex":   templateFuncFirstItemIndex(firstItemIndex, args),
+		"forNoKeyUpdate":   templateFuncLock(ForNoKeyUpdate, counting),
+		"forUpdate":        templateFuncLock(ForUpdate, counting),
+		"join":             templateFuncJoin(joinFrames),
+		"jsonAgg":          templateFuncJSONAgg,
+		"jsonb":            templateFuncJSONB(args),
+		"jsonbContainedBy": templateFuncJSONBContainedBy(args),
+		"jsonbContains":    templateFuncJSONBContains(args),
+		"lock":             templateFuncLockClause(counting),
+		"notDeleted":       templateFuncNotDeleted(includeDeleted),
+		"pageSize":         templateFuncPageSize(pageSize, args),
+		"sep":              templateFuncSep(joinFrames),
+		"tenant":           templateFuncTenant(tenant, args),
+		"tsHeadline":       templateFuncTSHeadline(args),
+		"tsMatch":          templateFuncTSMatch(args),
+		"tsQuery":          templateFuncTSQuery(args),
+		"tsRank":           templateFuncTSRank(args),
 	}
 }
 
@@ -155,6 +233,101 @@ func templateFuncJoin(frames *[]joinFrame) func(sep string) string {
 	}
 }
 
+// templateFuncJSONAgg aggregates expr into a JSON array, ignoring the rows
+// where key is NULL, such as from a LEFT JOIN without a match, and returning
+// an empty array rather than NULL when there are no rows.
+func templateFuncJSONAgg(expr string, key string) string {
+	return "COALESCE(json_agg(" + expr + ") FILTER (WHERE " + key + " IS NOT NULL), '[]')"
+}
+
+func templateFuncJSONB(args *[]any) func(arg any) (string, error) {
+	return func(arg any) (string, error) {
+		data, err := json.Marshal(arg)
+		if err != nil {
+			return "", err
+		}
+
+		*args = append(*args, string(data))
+		return "$" + strconv.Itoa(len(*args)) + "::jsonb", nil
+	}
+}
+
+func templateFuncJSONBContainedBy(args *[]any) func(column string, arg any) (string, error) {
+	return func(column string, arg any) (string, error) {
+		placeholder, err := templateFuncJSONB(args)(arg)
+		if err != nil {
+			return "", err
+		}
+
+		return column + " <@ " + placeholder, nil
+	}
+}
+
+func templateFuncJSONBContains(args *[]any) func(column string, arg any) (string, error) {
+	return func(column string, arg any) (string, error) {
+		placeholder, err := templateFuncJSONB(args)(arg)
+		if err != nil {
+			return "", err
+		}
+
+		return column + " @> " + placeholder, nil
+	}
+}
+
+// templateFuncLock locks the rows with the strength and an optional wait,
+// such as `{{ forUpdate "SKIP LOCKED" }}`. Counting queries cannot lock rows,
+// so nothing is written when counting.
+func templateFuncLock(strength LockStrength, counting bool) func(wait ...string) (string, error) {
+	return func(wait ...string) (string, error) {
+		if len(wait) > 1 {
+			return "", fmt.Errorf("%w: %s takes at most one wait", ErrTemplate, strength)
+		}
+
+		lock := Lock{Strength: strength}
+		if len(wait) == 1 {
+			lock.Wait = LockWait(wait[0])
+		}
+
+		return templateFuncLockClause(counting)(lock)
+	}
+}
+
+// templateFuncLockClause writes a Lock, such as `{{ lock .Lock }}`.
+func templateFuncLockClause(counting bool) func(lock Lock) (string, error) {
+	return func(lock Lock) (string, error) {
+		clause, err := lock.Clause()
+		if err != nil {
+			return "", err
+		}
+
+		if counting {
+			return "", nil
+		}
+
+		return clause, nil
+	}
+}
+
+// templateFuncNotDeleted excludes soft deleted rows, optionally qualifying
+// the column with a table alias, such as `{{ notDeleted "v" }}`.
+func templateFuncNotDeleted(includeDeleted bool) func(alias ...string) (string, error) {
+	return func(alias ...string) (string, error) {
+		if len(alias) > 1 {
+			return "", fmt.Errorf("%w: notDeleted takes at most one alias", ErrTemplate)
+		}
+
+		if includeDeleted {
+			return "TRUE", nil
+		}
+
+		if len(alias) == 1 {
+			return alias[0] + "." + DeletedAtColumn + " IS NULL", nil
+		}
+
+		return DeletedAtColumn + " IS NULL", nil
+	}
+}
+
 func templateFuncPageSize(pageSize *int64, args *[]any) func() (string, error) {
 	return func() (string, error) {
 		if pageSize == nil {
@@ -181,3 +354,67 @@ func templateFuncSep(frames *[]joinFrame) func() (string, error) {
 		return "", nil
 	}
 }
+
+func templateFuncTenant(tenant *string, args *[]any) func() (string, error) {
+	return func() (string, error) {
+		if tenant == nil {
+			return "", fmt.Errorf("%w: tenant", ErrTemplateFuncNotAvail)
+		}
+
+		*args = append(*args, *tenant)
+		return "$" + strconv.Itoa(len(*args)), nil
+	}
+}
+
+// templateFuncTSHeadline highlights the matches in the document, such as
+// `{{ tsHeadline "body" .Search "MaxWords=20" }}`, with optional ts_headline
+// options.
+func templateFuncTSHeadline(args *[]any) func(document string, search Search, options ...string) (string, error) {
+	return func(document string, search Search, options ...string) (string, error) {
+		if len(options) > 1 {
+			return "", fmt.Errorf("%w: tsHeadline takes at most one options string", ErrTemplate)
+		}
+
+		sql := "ts_headline("
+		if search.Config != "" {
+			*args = append(*args, search.Config)
+			sql += "$" + strconv.Itoa(len(*args)) + "::regconfig, "
+		}
+
+		sql += document + ", " + templateFuncTSQuery(args)(search)
+		if len(options) == 1 {
+			*args = append(*args, options[0])
+			sql += ", $" + strconv.Itoa(len(*args))
+		}
+
+		return sql + ")", nil
+	}
+}
+
+// templateFuncTSMatch matches a tsvector column or expression, such as
+// `{{ tsMatch "document" .Search }}`.
+func templateFuncTSMatch(args *[]any) func(vector string, search Search) string {
+	return func(vector string, search Search) string {
+		return vector + " @@ " + templateFuncTSQuery(args)(search)
+	}
+}
+
+func templateFuncTSQuery(args *[]any) func(search Search) string {
+	return func(search Search) string {
+		if search.Config == "" {
+			*args = append(*args, search.Query)
+			return "websearch_to_tsquery($" + strconv.Itoa(len(*args)) + ")"
+		}
+
+		*args = append(*args, search.Config, search.Query)
+		return "websearch_to_tsquery($" + strconv.Itoa(len(*args)-1) + "::regconfig, $" + strconv.Itoa(len(*args)) + ")"
+	}
+}
+
+// templateFuncTSRank ranks the match by cover density, such as
+// `ORDER BY {{ tsRank "document" .Search }} DESC`.
+func templateFuncTSRank(args *[]any) func(vector string, search Search) string {
+	return func(vector string, search Search) string {
+		return "ts_rank_cd(" + vector + ", " + templateFuncTSQuery(args)(search) + ")"
+	}
+}
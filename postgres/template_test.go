@@ -1,8 +1,12 @@
 package postgres
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeremybower/go-common/nilable"
 	"github.com/jeremybower/go-common/optional"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,6 +20,14 @@ func TestTemplateParse(t *testing.T) {
 	assert.Panics(t, func() { MustParse("SELECT * FROM table WHERE id = {{ unknown }}") })
 }
 
+func TestTemplateParseNamed(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", MustParse("SELECT 1").Name())
+	assert.Equal(t, "select_one", MustParseNamed("select_one", "SELECT 1").Name())
+	assert.Panics(t, func() { MustParseNamed("invalid", "SELECT {{ arg .ID") })
+}
+
 func TestTemplateExecute(t *testing.T) {
 	t.Parallel()
 
@@ -34,6 +46,13 @@ func TestTemplateExecute(t *testing.T) {
 			expectedSQL:  `SELECT * FROM table WHERE id = $1`,
 			expectedArgs: []any{"123"},
 		},
+		{
+			name:         "nullable",
+			text:         `UPDATE table SET name = {{ arg .Name }}, note = {{ arg .Note }}`,
+			data:         map[string]any{"Name": ToText(optional.NewValue("Alice")), "Note": ToText(nilable.NilValue[string]())},
+			expectedSQL:  `UPDATE table SET name = $1, note = $2`,
+			expectedArgs: []any{pgtype.Text{String: "Alice", Valid: true}, pgtype.Text{}},
+		},
 		{
 			name:         "join",
 			text:         `SELECT * FROM {{- join "AND" -}} {{ sep }} tableA {{ sep }} tableB {{ endJoin -}} WHERE id = {{ arg .ID }}`,
@@ -57,6 +76,53 @@ func TestTemplateExecute(t *testing.T) {
 			expectedSQL:  "",
 			expectedArgs: nil,
 		},
+		{
+			name:         "jsonAgg",
+			text:         `SELECT o.id, {{ jsonAgg "i ORDER BY i.id" "i.id" }} AS items FROM orders o LEFT JOIN items i ON i.order_id = o.id GROUP BY o.id`,
+			data:         map[string]any{},
+			expectedSQL:  `SELECT o.id, COALESCE(json_agg(i ORDER BY i.id) FILTER (WHERE i.id IS NOT NULL), '[]') AS items FROM orders o LEFT JOIN items i ON i.order_id = o.id GROUP BY o.id`,
+			expectedArgs: nil,
+		},
+		{
+			name:         "jsonb",
+			text:         `SELECT * FROM table WHERE data = {{ jsonb .Data }} AND {{ jsonbContains "data" .Data }} AND {{ jsonbContainedBy "data" .Data }}`,
+			data:         map[string]any{"Data": map[string]any{"a": 1}},
+			expectedSQL:  `SELECT * FROM table WHERE data = $1::jsonb AND data @> $2::jsonb AND data <@ $3::jsonb`,
+			expectedArgs: []any{`{"a":1}`, `{"a":1}`, `{"a":1}`},
+		},
+		{
+			name:         "tsMatch",
+			text:         `SELECT * FROM docs WHERE {{ tsMatch "document" .Search }} ORDER BY {{ tsRank "document" .Search }} DESC`,
+			data:         map[string]any{"Search": NewSearch("english", `"fat cat" -rat`)},
+			expectedSQL:  `SELECT * FROM docs WHERE document @@ websearch_to_tsquery($1::regconfig, $2) ORDER BY ts_rank_cd(document, websearch_to_tsquery($3::regconfig, $4)) DESC`,
+			expectedArgs: []any{"english", `"fat cat" -rat`, "english", `"fat cat" -rat`},
+		},
+		{
+			name:         "tsHeadline",
+			text:         `SELECT {{ tsHeadline "body" .Search "MaxWords=5" }}, {{ tsHeadline "body" .Default }} FROM docs`,
+			data:         map[string]any{"Search": NewSearch("english", "cat"), "Default": NewSearch("", "rat")},
+			expectedSQL:  `SELECT ts_headline($1::regconfig, body, websearch_to_tsquery($2::regconfig, $3), $4), ts_headline(body, websearch_to_tsquery($5)) FROM docs`,
+			expectedArgs: []any{"english", "english", "cat", "MaxWords=5", "rat"},
+		},
+		{
+			name:        "tsHeadline options",
+			text:        `SELECT {{ tsHeadline "body" .Search "a" "b" }} FROM docs`,
+			data:        map[string]any{"Search": NewSearch("english", "cat")},
+			expectedErr: ErrTemplate,
+		},
+		{
+			name:         "forUpdate",
+			text:         `SELECT * FROM table {{ forUpdate }} / {{ forUpdate "NOWAIT" }} / {{ forNoKeyUpdate "SKIP LOCKED" }} / {{ lock .Lock }}`,
+			data:         map[string]any{"Lock": Lock{Strength: ForShare, Wait: SkipLocked}},
+			expectedSQL:  `SELECT * FROM table FOR UPDATE / FOR UPDATE NOWAIT / FOR NO KEY UPDATE SKIP LOCKED / FOR SHARE SKIP LOCKED`,
+			expectedArgs: nil,
+		},
+		{
+			name:        "forUpdate invalid wait",
+			text:        `SELECT * FROM table {{ forUpdate "WAIT" }}`,
+			data:        map[string]any{},
+			expectedErr: ErrInvalidLock,
+		},
 		{
 			name:         "sep not available",
 			text:         `SELECT * FROM tableA {{ sep }} tableB WHERE id = {{ arg .ID }}`,
@@ -84,6 +150,14 @@ func TestTemplateExecute(t *testing.T) {
 	}
 }
 
+func TestTemplateExecuteJSONBNotMarshalable(t *testing.T) {
+	t.Parallel()
+
+	var unsupportedTypeErr *json.UnsupportedTypeError
+	_, _, err := MustParse(`SELECT * FROM table WHERE {{ jsonbContains "data" .Data }}`).Execute(map[string]any{"Data": func() {}})
+	assert.ErrorAs(t, err, &unsupportedTypeErr)
+}
+
 func TestTemplateExecuteCount(t *testing.T) {
 	t.Parallel()
 
@@ -102,6 +176,13 @@ func TestTemplateExecuteCount(t *testing.T) {
 			expectedSQL:  `SELECT * FROM table WHERE id = $1`,
 			expectedArgs: []any{"123"},
 		},
+		{
+			name:         "forUpdate",
+			text:         `SELECT COUNT(*) FROM table WHERE id = {{ arg .ID }} {{ forUpdate "SKIP LOCKED" }}`,
+			data:         map[string]any{"ID": "123"},
+			expectedSQL:  `SELECT COUNT(*) FROM table WHERE id = $1 `,
+			expectedArgs: []any{"123"},
+		},
 		{
 			name:         "firstItemIndex not available",
 			text:         `SELECT * FROM table WHERE id = {{ arg .ID }} OFFSET {{ firstItemIndex }}`,
@@ -232,3 +313,52 @@ func BenchmarkExecTemplateComplex(b *testing.B) {
 		benchTemplSQL, benchTemplArgs, benchTemplErr = benchTempl.Execute(data)
 	}
 }
+
+func TestTemplateExecuteContextTenant(t *testing.T) {
+	t.Parallel()
+
+	tmpl := MustParse(`SELECT * FROM table WHERE tenant_id = {{ tenant }} AND id = {{ arg .ID }}`)
+
+	sql, args, err := tmpl.ExecuteContext(WithTenant(context.Background(), "acme"), map[string]any{"ID": 1})
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM table WHERE tenant_id = $1 AND id = $2`, sql)
+	assert.Equal(t, []any{"acme", 1}, args)
+
+	_, _, err = tmpl.Execute(map[string]any{"ID": 1})
+	assert.ErrorIs(t, err, ErrTemplateFuncNotAvail)
+
+	_, _, err = tmpl.ExecuteContext(WithTenant(context.Background(), ""), map[string]any{"ID": 1})
+	assert.ErrorIs(t, err, ErrTemplateFuncNotAvail)
+}
+
+func TestTemplateExecuteShape(t *testing.T) {
+	t.Parallel()
+
+	tmpl := MustParse(`SELECT {{ if counting }}COUNT(*){{ else }}*{{ end }} FROM table WHERE tenant_id = {{ tenant }} AND id = {{ arg .ID }}{{ if not counting }} LIMIT {{ pageSize }} OFFSET {{ firstItemIndex }}{{ end }}`)
+
+	sql, err := tmpl.executeShape(map[string]any{"ID": 1}, false)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM table WHERE tenant_id = $1 AND id = $2 LIMIT $3 OFFSET $4`, sql)
+
+	sql, err = tmpl.executeShape(map[string]any{"ID": 1}, true)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT COUNT(*) FROM table WHERE tenant_id = $1 AND id = $2`, sql)
+}
+
+func TestTemplateExecuteContextNotDeleted(t *testing.T) {
+	t.Parallel()
+
+	tmpl := MustParse(`SELECT * FROM values v WHERE {{ notDeleted }} AND {{ notDeleted "v" }}`)
+
+	sql, args, err := tmpl.ExecuteContext(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM values v WHERE deleted_at IS NULL AND v.deleted_at IS NULL`, sql)
+	assert.Empty(t, args)
+
+	sql, _, err = tmpl.ExecuteContext(IncludeDeleted(context.Background()), nil)
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM values v WHERE TRUE AND TRUE`, sql)
+
+	_, _, err = MustParse(`{{ notDeleted "a" "b" }}`).Execute(nil)
+	assert.ErrorIs(t, err, ErrTemplate)
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var ErrTenant = errors.New("postgres tenant")
+var ErrTenantRequired = fmt.Errorf("%w: required", ErrTenant)
+
+// TenantSetting is the setting that holds the tenant for row-level security
+// policies, such as `USING (tenant_id = current_setting('app.tenant_id'))`.
+const TenantSetting = "app.tenant_id"
+
+const tenantKey = contextKey("tenant")
+
+// WithTenant sets the tenant for TenantQuerier and the tenant template
+// function. An empty tenant ID is not a tenant, so both fail rather than
+// scoping statements to it.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenantID)
+}
+
+func Tenant(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantKey).(string)
+	return tenantID, ok && tenantID != ""
+}
+
+// TenantQuerier sets TenantSetting to the context's tenant at the start of
+// every transaction. Statements outside of a transaction are run in their own
+// transaction so that the setting applies to them too. It fails with
+// ErrTenantRequired when the context has no tenant.
+type TenantQuerier struct {
+	querier Querier
+}
+
+func NewTenantQuerier(querier Querier) *TenantQuerier {
+	return &TenantQuerier{querier: querier}
+}
+
+// wrapped returns the querier that the tenant's transactions are begun on.
+func (q *TenantQuerier) wrapped() Querier {
+	return q.querier
+}
+
+func (q *TenantQuerier) Begin(ctx context.Context) (pgx.Tx, error) {
+	tenantID, ok := Tenant(ctx)
+	if !ok {
+		return nil, ErrTenantRequired
+	}
+
+	tx, err := q.querier.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The setting is local, so it is reset when the transaction ends.
+	if _, err := tx.Exec(ctx, "SELECT set_config($1, $2, true)", TenantSetting, tenantID); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	// Success.
+	return tx, nil
+}
+
+func (q *TenantQuerier) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx, err := q.Begin(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+
+	// Success.
+	return tag, nil
+}
+
+func (q *TenantQuerier) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx, err := q.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+
+	// Success.
+	return &tenantRows{Rows: rows, ctx: ctx, tx: tx}, nil
+}
+
+func (q *TenantQuerier) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := q.Query(ctx, sql, args...)
+	return &queryRow{rows: rows, err: err}
+}
+
+// tenantRows ends the transaction when the rows are closed.
+type tenantRows struct {
+	pgx.Rows
+	ctx    context.Context
+	tx     pgx.Tx
+	closed bool
+	err    error
+}
+
+func (r *tenantRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+
+	r.Close()
+	return false
+}
+
+func (r *tenantRows) Close() {
+	if r.closed {
+		return
+	}
+
+	r.closed = true
+	r.Rows.Close()
+	if r.Rows.Err() != nil {
+		_ = r.tx.Rollback(r.ctx)
+		return
+	}
+
+	r.err = r.tx.Commit(r.ctx)
+}
+
+func (r *tenantRows) Err() error {
+	if err := r.Rows.Err(); err != nil {
+		return err
+	}
+
+	return r.err
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTenant(t *testing.T) {
+	t.Parallel()
+
+	_, ok := Tenant(context.Background())
+	assert.False(t, ok)
+
+	tenantID, ok := Tenant(WithTenant(context.Background(), "acme"))
+	assert.True(t, ok)
+	assert.Equal(t, "acme", tenantID)
+
+	_, ok = Tenant(WithTenant(context.Background(), ""))
+	assert.False(t, ok)
+}
+
+func TestTenantQuerierRequiresTenant(t *testing.T) {
+	t.Parallel()
+
+	inner := &fakeQuerier{}
+	q := NewTenantQuerier(inner)
+
+	ctx := context.Background()
+	_, err := q.Begin(ctx)
+	assert.ErrorIs(t, err, ErrTenantRequired)
+
+	_, err = q.Exec(ctx, "DELETE FROM values")
+	assert.ErrorIs(t, err, ErrTenantRequired)
+
+	_, err = q.Query(ctx, "SELECT * FROM values")
+	assert.ErrorIs(t, err, ErrTenantRequired)
+
+	var id int64
+	err = q.QueryRow(ctx, "SELECT id FROM values").Scan(&id)
+	assert.ErrorIs(t, err, ErrTenantRequired)
+
+	_, err = q.Begin(WithTenant(ctx, ""))
+	assert.ErrorIs(t, err, ErrTenantRequired)
+
+	assert.Equal(t, 0, inner.calls)
+}
+
+func TestTenantQuerierRowLevelSecurity(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	// Superusers bypass row-level security, so use a new role.
+	ctx := context.Background()
+	role := "tenant-" + uuid.NewString()
+	_, err := dbPool.Exec(ctx, fmt.Sprintf(`
+		CREATE TABLE notes (id BIGSERIAL PRIMARY KEY, tenant_id TEXT NOT NULL, body TEXT NOT NULL);
+		ALTER TABLE notes ENABLE ROW LEVEL SECURITY;
+		CREATE POLICY notes_tenant ON notes USING (tenant_id = current_setting('app.tenant_id'));
+		CREATE ROLE %[1]q;
+		GRANT SELECT, INSERT, DELETE ON notes TO %[1]q;
+		GRANT USAGE ON SEQUENCE notes_id_seq TO %[1]q;
+		INSERT INTO notes (tenant_id, body) VALUES ('acme', 'a1'), ('acme', 'a2'), ('globex', 'g1');
+	`, role))
+	require.NoError(t, err)
+	defer func() {
+		_, _ = dbPool.Exec(ctx, fmt.Sprintf(`DROP OWNED BY %[1]q; DROP ROLE %[1]q;`, role))
+	}()
+
+	conn, err := dbPool.Acquire(ctx)
+	require.NoError(t, err)
+	defer conn.Release()
+	defer func() { _, _ = conn.Exec(ctx, "RESET ROLE") }()
+
+	_, err = conn.Exec(ctx, fmt.Sprintf("SET ROLE %q", role))
+	require.NoError(t, err)
+
+	q := NewTenantQuerier(conn)
+	acme := WithTenant(ctx, "acme")
+	globex := WithTenant(ctx, "globex")
+
+	// Reads only see the tenant's rows.
+	c, err := Count(acme, q, "SELECT COUNT(*) FROM notes")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+
+	c, err = CountT(globex, q, MustParse("SELECT COUNT(*) FROM notes WHERE tenant_id = {{ tenant }}"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), c)
+
+	// Writes only affect the tenant's rows.
+	n, err := Exec(globex, q, "DELETE FROM notes")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	c, err = Count(acme, q, "SELECT COUNT(*) FROM notes")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), c)
+
+	// The setting does not outlive the transaction.
+	var setting *string
+	err = conn.QueryRow(ctx, "SELECT NULLIF(current_setting('app.tenant_id', true), '')").Scan(&setting)
+	require.NoError(t, err)
+	assert.Nil(t, setting)
+}
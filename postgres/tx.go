@@ -0,0 +1,24 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// WithTx runs fn in a transaction that is committed when fn succeeds and
+// rolled back otherwise. Beginning on a pgx.Tx creates a savepoint.
+func WithTx(ctx context.Context, querier Querier, fn func(tx pgx.Tx) error) error {
+	tx, err := querier.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	// Success.
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTx(t *testing.T) {
+	t.Parallel()
+
+	dbPool := databasePoolForTesting(t)
+	defer dbPool.Close()
+
+	ctx := context.Background()
+	err := WithTx(ctx, dbPool, func(tx pgx.Tx) error {
+		_, err := Exec(ctx, tx, "INSERT INTO values (name, value) VALUES ('name', 'committed')")
+		return err
+	})
+	require.NoError(t, err)
+
+	errRollback := errors.New("rollback")
+	err = WithTx(ctx, dbPool, func(tx pgx.Tx) error {
+		if _, err := Exec(ctx, tx, "INSERT INTO values (name, value) VALUES ('name', 'rolled back')"); err != nil {
+			return err
+		}
+
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	rows, err := ReadMany[valueRow](ctx, dbPool, "SELECT * FROM values")
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "committed", rows[0].Value)
+}
+
+func TestWithTxBeginError(t *testing.T) {
+	t.Parallel()
+
+	errBegin := errors.New("begin")
+	err := WithTx(context.Background(), &fakeQuerier{err: errBegin}, func(tx pgx.Tx) error {
+		t.Fatal("fn should not be called")
+		return nil
+	})
+	assert.ErrorIs(t, err, errBegin)
+}
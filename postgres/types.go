@@ -1,15 +1,51 @@
 package postgres
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"net/netip"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeremybower/go-common"
 	"github.com/jeremybower/go-common/nilable"
+	"github.com/jeremybower/go-common/optional"
 )
 
+// ErrUnexpectedNull is returned by the Try* converters, which mirror the
+// Required* converters but return an error instead of panicking on NULL.
+var ErrUnexpectedNull = errors.New("unexpected null")
+
+func unexpectedNull(typeName string) error {
+	return fmt.Errorf("%w: %s", ErrUnexpectedNull, typeName)
+}
+
+// Nullable is implemented by nilable.Value and optional.Value. The To*
+// converters use it to build pgtype values for query arguments, where a nil
+// value becomes NULL, and the From* converters do the same for pointers.
+// Only the conversions that can fail return an error: ints that overflow an
+// int4, strings that are not UUIDs and invalid dates.
+type Nullable[T any] interface {
+	OrNil() *T
+}
+
+// ToAny returns the value or an untyped nil so that it can be passed directly
+// as a query argument.
+func ToAny[T any](v Nullable[T]) any {
+	return FromAny(v.OrNil())
+}
+
+func FromAny[T any](p *T) any {
+	if p != nil {
+		return *p
+	}
+
+	return nil
+}
+
 //-----------------------------------------------------------------------------
 // Array
 //-----------------------------------------------------------------------------
@@ -22,6 +58,14 @@ func RequiredArray[T any](a pgtype.Array[T]) []T {
 	return a.Elements
 }
 
+func TryArray[T any](a pgtype.Array[T]) ([]T, error) {
+	if !a.Valid {
+		return nil, unexpectedNull("array")
+	}
+
+	return a.Elements, nil
+}
+
 func NilableArray[T any](a pgtype.Array[T]) nilable.Slice[T] {
 	if !a.Valid {
 		return nilable.NilSlice[T]()
@@ -38,6 +82,14 @@ func RequiredFlatArray[T any](a pgtype.FlatArray[T]) []T {
 	return a
 }
 
+func TryFlatArray[T any](a pgtype.FlatArray[T]) ([]T, error) {
+	if a == nil {
+		return nil, unexpectedNull("array")
+	}
+
+	return a, nil
+}
+
 func NilableFlatArray[T any](a pgtype.FlatArray[T]) nilable.Slice[T] {
 	if a == nil {
 		return nilable.NilSlice[T]()
@@ -58,6 +110,14 @@ func RequiredBool(b pgtype.Bool) bool {
 	return b.Bool
 }
 
+func TryBool(b pgtype.Bool) (bool, error) {
+	if !b.Valid {
+		return false, unexpectedNull("bool")
+	}
+
+	return b.Bool, nil
+}
+
 func NilableBool(b pgtype.Bool) nilable.Value[bool] {
 	if !b.Valid {
 		return nilable.NilValue[bool]()
@@ -66,6 +126,76 @@ func NilableBool(b pgtype.Bool) nilable.Value[bool] {
 	return nilable.NewValue(&b.Bool)
 }
 
+func ToBool(v Nullable[bool]) pgtype.Bool {
+	return FromBool(v.OrNil())
+}
+
+func FromBool(p *bool) pgtype.Bool {
+	if p != nil {
+		return pgtype.Bool{Bool: *p, Valid: true}
+	}
+
+	return pgtype.Bool{}
+}
+
+//-----------------------------------------------------------------------------
+// Date
+//-----------------------------------------------------------------------------
+
+func RequiredDate(d pgtype.Date) (common.Date, error) {
+	if !d.Valid {
+		panic("date is required")
+	}
+
+	return toDate(d)
+}
+
+func TryDate(d pgtype.Date) (common.Date, error) {
+	if !d.Valid {
+		return common.Date{}, unexpectedNull("date")
+	}
+
+	return toDate(d)
+}
+
+func NilableDate(d pgtype.Date) (nilable.Value[common.Date], error) {
+	if !d.Valid {
+		return nilable.NilValue[common.Date](), nil
+	}
+
+	val, err := toDate(d)
+	if err != nil {
+		return nilable.InvalidValue[common.Date](), err
+	}
+
+	return nilable.NewValue(&val), nil
+}
+
+func ToDate(v Nullable[common.Date]) (pgtype.Date, error) {
+	return FromDate(v.OrNil())
+}
+
+func FromDate(p *common.Date) (pgtype.Date, error) {
+	if p == nil {
+		return pgtype.Date{}, nil
+	}
+
+	d := *p
+	if !d.Valid {
+		return pgtype.Date{}, fmt.Errorf("%w: %s", common.ErrInvalidDate, d)
+	}
+
+	return pgtype.Date{Time: time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC), Valid: true}, nil
+}
+
+func toDate(d pgtype.Date) (common.Date, error) {
+	if d.InfinityModifier != pgtype.Finite {
+		return common.Date{}, fmt.Errorf("%w: %s", ErrInfiniteTime, d.InfinityModifier)
+	}
+
+	return common.NewDate(d.Time.Year(), d.Time.Month(), d.Time.Day()), nil
+}
+
 //-----------------------------------------------------------------------------
 // Float4
 //-----------------------------------------------------------------------------
@@ -78,6 +208,14 @@ func RequiredFloat4[T ~float32](n pgtype.Float4) T {
 	return T(n.Float32)
 }
 
+func TryFloat4[T ~float32](n pgtype.Float4) (T, error) {
+	if !n.Valid {
+		return 0, unexpectedNull("float4")
+	}
+
+	return T(n.Float32), nil
+}
+
 func NilableFloat4[T ~float32](n pgtype.Float4) nilable.Value[T] {
 	if !n.Valid {
 		return nilable.NilValue[T]()
@@ -87,6 +225,18 @@ func NilableFloat4[T ~float32](n pgtype.Float4) nilable.Value[T] {
 	return nilable.NewValue(&val)
 }
 
+func ToFloat4[T ~float32](v Nullable[T]) pgtype.Float4 {
+	return FromFloat4(v.OrNil())
+}
+
+func FromFloat4[T ~float32](p *T) pgtype.Float4 {
+	if p != nil {
+		return pgtype.Float4{Float32: float32(*p), Valid: true}
+	}
+
+	return pgtype.Float4{}
+}
+
 //-----------------------------------------------------------------------------
 // Float8
 //-----------------------------------------------------------------------------
@@ -100,6 +250,14 @@ func RequiredFloat8[T ~float64](n pgtype.Float8) T {
 	return val
 }
 
+func TryFloat8[T ~float64](n pgtype.Float8) (T, error) {
+	if !n.Valid {
+		return 0, unexpectedNull("float8")
+	}
+
+	return T(n.Float64), nil
+}
+
 func NilableFloat8[T ~float64](n pgtype.Float8) nilable.Value[T] {
 	if !n.Valid {
 		return nilable.NilValue[T]()
@@ -109,6 +267,18 @@ func NilableFloat8[T ~float64](n pgtype.Float8) nilable.Value[T] {
 	return nilable.NewValue(&val)
 }
 
+func ToFloat8[T ~float64](v Nullable[T]) pgtype.Float8 {
+	return FromFloat8(v.OrNil())
+}
+
+func FromFloat8[T ~float64](p *T) pgtype.Float8 {
+	if p != nil {
+		return pgtype.Float8{Float64: float64(*p), Valid: true}
+	}
+
+	return pgtype.Float8{}
+}
+
 //-----------------------------------------------------------------------------
 // Int2
 //-----------------------------------------------------------------------------
@@ -121,6 +291,14 @@ func RequiredInt2[T ~int16](n pgtype.Int2) T {
 	return T(n.Int16)
 }
 
+func TryInt2[T ~int16](n pgtype.Int2) (T, error) {
+	if !n.Valid {
+		return 0, unexpectedNull("int2")
+	}
+
+	return T(n.Int16), nil
+}
+
 func NilableInt2[T ~int16](n pgtype.Int2) nilable.Value[T] {
 	if !n.Valid {
 		return nilable.NilValue[T]()
@@ -130,10 +308,24 @@ func NilableInt2[T ~int16](n pgtype.Int2) nilable.Value[T] {
 	return nilable.NewValue(&val)
 }
 
+func ToInt2[T ~int16](v Nullable[T]) pgtype.Int2 {
+	return FromInt2(v.OrNil())
+}
+
+func FromInt2[T ~int16](p *T) pgtype.Int2 {
+	if p != nil {
+		return pgtype.Int2{Int16: int16(*p), Valid: true}
+	}
+
+	return pgtype.Int2{}
+}
+
 //-----------------------------------------------------------------------------
 // Int4
 //-----------------------------------------------------------------------------
 
+var ErrIntOverflow = errors.New("integer overflow")
+
 func RequiredInt4[T ~int | ~int32](n pgtype.Int4) T {
 	if !n.Valid {
 		panic("invalid value")
@@ -142,6 +334,14 @@ func RequiredInt4[T ~int | ~int32](n pgtype.Int4) T {
 	return T(n.Int32)
 }
 
+func TryInt4[T ~int | ~int32](n pgtype.Int4) (T, error) {
+	if !n.Valid {
+		return 0, unexpectedNull("int4")
+	}
+
+	return T(n.Int32), nil
+}
+
 func NilableInt4[T ~int | ~int32](n pgtype.Int4) nilable.Value[T] {
 	if !n.Valid {
 		return nilable.NilValue[T]()
@@ -151,6 +351,24 @@ func NilableInt4[T ~int | ~int32](n pgtype.Int4) nilable.Value[T] {
 	return nilable.NewValue(&val)
 }
 
+// ToInt4 fails with ErrIntOverflow when an int does not fit in an int4.
+func ToInt4[T ~int | ~int32](v Nullable[T]) (pgtype.Int4, error) {
+	return FromInt4(v.OrNil())
+}
+
+// FromInt4 fails with ErrIntOverflow when an int does not fit in an int4.
+func FromInt4[T ~int | ~int32](p *T) (pgtype.Int4, error) {
+	if p == nil {
+		return pgtype.Int4{}, nil
+	}
+
+	if int64(*p) < math.MinInt32 || int64(*p) > math.MaxInt32 {
+		return pgtype.Int4{}, fmt.Errorf("%w: %d", ErrIntOverflow, *p)
+	}
+
+	return pgtype.Int4{Int32: int32(*p), Valid: true}, nil
+}
+
 //-----------------------------------------------------------------------------
 // Int8
 //-----------------------------------------------------------------------------
@@ -163,6 +381,14 @@ func RequiredInt8[T ~int64](n pgtype.Int8) T {
 	return T(n.Int64)
 }
 
+func TryInt8[T ~int64](n pgtype.Int8) (T, error) {
+	if !n.Valid {
+		return 0, unexpectedNull("int8")
+	}
+
+	return T(n.Int64), nil
+}
+
 func NilableInt8[T ~int64](n pgtype.Int8) nilable.Value[T] {
 	if !n.Valid {
 		return nilable.NilValue[T]()
@@ -172,6 +398,91 @@ func NilableInt8[T ~int64](n pgtype.Int8) nilable.Value[T] {
 	return nilable.NewValue(&val)
 }
 
+func ToInt8[T ~int64](v Nullable[T]) pgtype.Int8 {
+	return FromInt8(v.OrNil())
+}
+
+func FromInt8[T ~int64](p *T) pgtype.Int8 {
+	if p != nil {
+		return pgtype.Int8{Int64: int64(*p), Valid: true}
+	}
+
+	return pgtype.Int8{}
+}
+
+//-----------------------------------------------------------------------------
+// Interval
+//-----------------------------------------------------------------------------
+
+var ErrInterval = errors.New("postgres interval")
+var ErrIntervalMonths = fmt.Errorf("%w: months cannot be converted to a duration", ErrInterval)
+var ErrIntervalOverflow = fmt.Errorf("%w: overflows a duration", ErrInterval)
+
+func RequiredInterval(i pgtype.Interval) (time.Duration, error) {
+	if !i.Valid {
+		panic("interval is required")
+	}
+
+	return toDuration(i)
+}
+
+func TryInterval(i pgtype.Interval) (time.Duration, error) {
+	if !i.Valid {
+		return 0, unexpectedNull("interval")
+	}
+
+	return toDuration(i)
+}
+
+func NilableInterval(i pgtype.Interval) (nilable.Value[time.Duration], error) {
+	if !i.Valid {
+		return nilable.NilValue[time.Duration](), nil
+	}
+
+	val, err := toDuration(i)
+	if err != nil {
+		return nilable.InvalidValue[time.Duration](), err
+	}
+
+	return nilable.NewValue(&val), nil
+}
+
+func ToInterval(v Nullable[time.Duration]) pgtype.Interval {
+	return FromInterval(v.OrNil())
+}
+
+func FromInterval(p *time.Duration) pgtype.Interval {
+	if p != nil {
+		return pgtype.Interval{Microseconds: int64(*p / time.Microsecond), Valid: true}
+	}
+
+	return pgtype.Interval{}
+}
+
+// toDuration converts the interval, treating each day as 24 hours. Months
+// have no fixed length, so they are rejected.
+func toDuration(i pgtype.Interval) (time.Duration, error) {
+	if i.Months != 0 {
+		return 0, fmt.Errorf("%w: %d", ErrIntervalMonths, i.Months)
+	}
+
+	const maxMicroseconds = math.MaxInt64 / int64(time.Microsecond)
+	const microsecondsPerDay = int64(24 * time.Hour / time.Microsecond)
+	const maxDays = maxMicroseconds / microsecondsPerDay
+
+	if int64(i.Days) > maxDays || int64(i.Days) < -maxDays ||
+		i.Microseconds > maxMicroseconds || i.Microseconds < -maxMicroseconds {
+		return 0, ErrIntervalOverflow
+	}
+
+	total := int64(i.Days)*microsecondsPerDay + i.Microseconds
+	if total > maxMicroseconds || total < -maxMicroseconds {
+		return 0, ErrIntervalOverflow
+	}
+
+	return time.Duration(total) * time.Microsecond, nil
+}
+
 //-----------------------------------------------------------------------------
 // IP Address
 //-----------------------------------------------------------------------------
@@ -184,6 +495,14 @@ func RequiredIPAddr(ip netip.Addr) netip.Addr {
 	return ip
 }
 
+func TryIPAddr(ip netip.Addr) (netip.Addr, error) {
+	if !ip.IsValid() {
+		return netip.Addr{}, unexpectedNull("inet")
+	}
+
+	return ip, nil
+}
+
 func NilableIPAddr(ip netip.Addr) nilable.Value[netip.Addr] {
 	if !ip.IsValid() {
 		return nilable.NilValue[netip.Addr]()
@@ -204,6 +523,14 @@ func RequiredJSON(value map[string]any) map[string]any {
 	return value
 }
 
+func TryJSON(value map[string]any) (map[string]any, error) {
+	if value == nil {
+		return nil, unexpectedNull("json")
+	}
+
+	return value, nil
+}
+
 func NilableJSON(value map[string]any) nilable.Map[string, any] {
 	if value == nil {
 		return nilable.NilMap[string, any]()
@@ -212,6 +539,129 @@ func NilableJSON(value map[string]any) nilable.Map[string, any] {
 	return nilable.NewMap(value)
 }
 
+func RequiredJSONB[T any](j JSONB[T]) T {
+	if !j.Valid {
+		panic(fmt.Sprintf("invalid json of type %T", j))
+	}
+
+	return j.Data
+}
+
+func TryJSONB[T any](j JSONB[T]) (T, error) {
+	if !j.Valid {
+		var zero T
+		return zero, unexpectedNull("jsonb")
+	}
+
+	return j.Data, nil
+}
+
+func NilableJSONB[T any](j JSONB[T]) nilable.Value[T] {
+	if !j.Valid {
+		return nilable.NilValue[T]()
+	}
+
+	val := j.Data
+	return nilable.NewValue(&val)
+}
+
+func OptionalJSONB[T any](j JSONB[T]) optional.Value[T] {
+	if !j.Valid {
+		return optional.InvalidValue[T]()
+	}
+
+	return optional.NewValue(j.Data)
+}
+
+//-----------------------------------------------------------------------------
+// Numeric
+//-----------------------------------------------------------------------------
+
+var ErrNumeric = errors.New("postgres numeric")
+var ErrNumericNaN = fmt.Errorf("%w: NaN", ErrNumeric)
+var ErrNumericInfinity = fmt.Errorf("%w: infinite", ErrNumeric)
+var ErrNumericNotInteger = fmt.Errorf("%w: not an integer", ErrNumeric)
+
+// RequiredNumeric panics when the numeric is NULL, like the other Required
+// functions, and returns the error from fn, such as ToDecimal failing for NaN
+// and infinite values.
+func RequiredNumeric[T any](n pgtype.Numeric, fn func(pgtype.Numeric) (T, error)) (T, error) {
+	if !n.Valid {
+		panic("numeric is required")
+	}
+
+	return fn(n)
+}
+
+func TryNumeric[T any](n pgtype.Numeric, fn func(pgtype.Numeric) (T, error)) (T, error) {
+	if !n.Valid {
+		var zero T
+		return zero, unexpectedNull("numeric")
+	}
+
+	return fn(n)
+}
+
+func NilableNumeric[T any](n pgtype.Numeric, fn func(pgtype.Numeric) (T, error)) (nilable.Value[T], error) {
+	if !n.Valid {
+		return nilable.NilValue[T](), nil
+	}
+
+	val, err := fn(n)
+	if err != nil {
+		return nilable.InvalidValue[T](), err
+	}
+
+	return nilable.NewValue(&val), nil
+}
+
+func ToDecimal(n pgtype.Numeric) (Decimal, error) {
+	if !n.Valid {
+		return Decimal{}, fmt.Errorf("%w: null", ErrNumeric)
+	}
+
+	if n.NaN {
+		return Decimal{}, ErrNumericNaN
+	}
+
+	if n.InfinityModifier != pgtype.Finite {
+		return Decimal{}, fmt.Errorf("%w: %s", ErrNumericInfinity, n.InfinityModifier)
+	}
+
+	if n.Int == nil {
+		return NewDecimalFromBigInt(new(big.Int), n.Exp)
+	}
+
+	return NewDecimalFromBigInt(n.Int, n.Exp)
+}
+
+func ToDecimalString(n pgtype.Numeric) (string, error) {
+	d, err := ToDecimal(n)
+	if err != nil {
+		return "", err
+	}
+
+	return d.String(), nil
+}
+
+func ToRat(n pgtype.Numeric) (*big.Rat, error) {
+	d, err := ToDecimal(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Rat(), nil
+}
+
+func ToBigInt(n pgtype.Numeric) (*big.Int, error) {
+	d, err := ToDecimal(n)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.BigInt()
+}
+
 //-----------------------------------------------------------------------------
 // Point
 //-----------------------------------------------------------------------------
@@ -224,6 +674,15 @@ func RequiredPoint[T any](p pgtype.Point, fn func(pgtype.Point) T) T {
 	return fn(p)
 }
 
+func TryPoint[T any](p pgtype.Point, fn func(pgtype.Point) T) (T, error) {
+	if !p.Valid {
+		var zero T
+		return zero, unexpectedNull("point")
+	}
+
+	return fn(p), nil
+}
+
 func NilablePoint[T any](p pgtype.Point, fn func(pgtype.Point) T) nilable.Value[T] {
 	if !p.Valid {
 		return nilable.NilValue[T]()
@@ -237,6 +696,55 @@ func ToVec2(p pgtype.Point) pgtype.Vec2 {
 	return p.P
 }
 
+//-----------------------------------------------------------------------------
+// Range
+//-----------------------------------------------------------------------------
+
+func RequiredRange[T RangeElement, U any](r pgtype.Range[U], fn func(U) T) Range[T] {
+	if !r.Valid {
+		panic(fmt.Sprintf("invalid range of type %T", r))
+	}
+
+	return toRange(r, fn)
+}
+
+func TryRange[T RangeElement, U any](r pgtype.Range[U], fn func(U) T) (Range[T], error) {
+	if !r.Valid {
+		return Range[T]{}, unexpectedNull("range")
+	}
+
+	return toRange(r, fn), nil
+}
+
+func NilableRange[T RangeElement, U any](r pgtype.Range[U], fn func(U) T) nilable.Value[Range[T]] {
+	if !r.Valid {
+		return nilable.NilValue[Range[T]]()
+	}
+
+	val := toRange(r, fn)
+	return nilable.NewValue(&val)
+}
+
+// toRange converts the range, only calling fn for bounded ends.
+func toRange[T RangeElement, U any](r pgtype.Range[U], fn func(U) T) Range[T] {
+	if r.LowerType == pgtype.Empty || r.UpperType == pgtype.Empty {
+		return EmptyRange[T]()
+	}
+
+	var val Range[T]
+	if r.LowerType != pgtype.Unbounded {
+		val.Lower = fn(r.Lower)
+		val.LowerType = boundType(r.LowerType == pgtype.Inclusive)
+	}
+
+	if r.UpperType != pgtype.Unbounded {
+		val.Upper = fn(r.Upper)
+		val.UpperType = boundType(r.UpperType == pgtype.Inclusive)
+	}
+
+	return val.Canonical()
+}
+
 //-----------------------------------------------------------------------------
 // Text
 //-----------------------------------------------------------------------------
@@ -249,6 +757,14 @@ func RequiredText[T ~string](t pgtype.Text) T {
 	return T(t.String)
 }
 
+func TryText[T ~string](t pgtype.Text) (T, error) {
+	if !t.Valid {
+		return "", unexpectedNull("text")
+	}
+
+	return T(t.String), nil
+}
+
 func NilableText[T ~string](t pgtype.Text) nilable.Value[T] {
 	if !t.Valid {
 		return nilable.NilValue[T]()
@@ -258,6 +774,62 @@ func NilableText[T ~string](t pgtype.Text) nilable.Value[T] {
 	return nilable.NewValue(&str)
 }
 
+func ToText[T ~string](v Nullable[T]) pgtype.Text {
+	return FromText(v.OrNil())
+}
+
+func FromText[T ~string](p *T) pgtype.Text {
+	if p != nil {
+		return pgtype.Text{String: string(*p), Valid: true}
+	}
+
+	return pgtype.Text{}
+}
+
+//-----------------------------------------------------------------------------
+// Time
+//-----------------------------------------------------------------------------
+
+// RequiredTime returns the time of day as the duration since midnight.
+func RequiredTime(t pgtype.Time) time.Duration {
+	if !t.Valid {
+		panic("time is required")
+	}
+
+	return time.Duration(t.Microseconds) * time.Microsecond
+}
+
+func TryTime(t pgtype.Time) (time.Duration, error) {
+	if !t.Valid {
+		return 0, unexpectedNull("time")
+	}
+
+	return time.Duration(t.Microseconds) * time.Microsecond, nil
+}
+
+func NilableTime(t pgtype.Time) nilable.Value[time.Duration] {
+	if !t.Valid {
+		return nilable.NilValue[time.Duration]()
+	}
+
+	val := time.Duration(t.Microseconds) * time.Microsecond
+	return nilable.NewValue(&val)
+}
+
+// ToTime converts a duration since midnight to a time of day.
+func ToTime(v Nullable[time.Duration]) pgtype.Time {
+	return FromTime(v.OrNil())
+}
+
+// FromTime converts a duration since midnight to a time of day.
+func FromTime(p *time.Duration) pgtype.Time {
+	if p != nil {
+		return pgtype.Time{Microseconds: int64(*p / time.Microsecond), Valid: true}
+	}
+
+	return pgtype.Time{}
+}
+
 //-----------------------------------------------------------------------------
 // Timestamp
 //-----------------------------------------------------------------------------
@@ -270,6 +842,14 @@ func RequiredTimestamp(t pgtype.Timestamp) time.Time {
 	return t.Time
 }
 
+func TryTimestamp(t pgtype.Timestamp) (time.Time, error) {
+	if !t.Valid {
+		return time.Time{}, unexpectedNull("timestamp")
+	}
+
+	return t.Time, nil
+}
+
 func NilableTimestamp(t pgtype.Timestamp) nilable.Value[time.Time] {
 	if !t.Valid {
 		return nilable.NilValue[time.Time]()
@@ -279,6 +859,75 @@ func NilableTimestamp(t pgtype.Timestamp) nilable.Value[time.Time] {
 	return nilable.NewValue(&time)
 }
 
+func ToTimestamp(v Nullable[time.Time]) pgtype.Timestamp {
+	return FromTimestamp(v.OrNil())
+}
+
+func FromTimestamp(p *time.Time) pgtype.Timestamp {
+	if p != nil {
+		return pgtype.Timestamp{Time: p.Truncate(TimePrecision), Valid: true}
+	}
+
+	return pgtype.Timestamp{}
+}
+
+//-----------------------------------------------------------------------------
+// Timestamptz
+//-----------------------------------------------------------------------------
+
+var ErrInfiniteTime = errors.New("infinite time")
+
+// RequiredTimestamptz returns the time normalized with Time. Postgres allows
+// infinite timestamps, which are returned as an error.
+func RequiredTimestamptz(t pgtype.Timestamptz) (time.Time, error) {
+	if !t.Valid {
+		panic("timestamptz is required")
+	}
+
+	return toTime(t)
+}
+
+func TryTimestamptz(t pgtype.Timestamptz) (time.Time, error) {
+	if !t.Valid {
+		return time.Time{}, unexpectedNull("timestamptz")
+	}
+
+	return toTime(t)
+}
+
+func NilableTimestamptz(t pgtype.Timestamptz) (nilable.Value[time.Time], error) {
+	if !t.Valid {
+		return nilable.NilValue[time.Time](), nil
+	}
+
+	val, err := toTime(t)
+	if err != nil {
+		return nilable.InvalidValue[time.Time](), err
+	}
+
+	return nilable.NewValue(&val), nil
+}
+
+func ToTimestamptz(v Nullable[time.Time]) pgtype.Timestamptz {
+	return FromTimestamptz(v.OrNil())
+}
+
+func FromTimestamptz(p *time.Time) pgtype.Timestamptz {
+	if p != nil {
+		return pgtype.Timestamptz{Time: Time(*p), Valid: true}
+	}
+
+	return pgtype.Timestamptz{}
+}
+
+func toTime(t pgtype.Timestamptz) (time.Time, error) {
+	if t.InfinityModifier != pgtype.Finite {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInfiniteTime, t.InfinityModifier)
+	}
+
+	return Time(t.Time), nil
+}
+
 //-----------------------------------------------------------------------------
 // UUID
 //-----------------------------------------------------------------------------
@@ -298,6 +947,19 @@ func RequiredUUID[T ~string](u pgtype.UUID) T {
 	return T(id.String())
 }
 
+func TryUUID[T ~string](u pgtype.UUID) (T, error) {
+	if !u.Valid {
+		return "", unexpectedNull("uuid")
+	}
+
+	id, err := uuid.FromBytes(u.Bytes[:])
+	if err != nil {
+		return "", err
+	}
+
+	return T(id.String()), nil
+}
+
 func NilableUUID[T ~string](u pgtype.UUID) nilable.Value[T] {
 	if !u.Valid {
 		return nilable.NilValue[T]()
@@ -313,3 +975,20 @@ func NilableUUID[T ~string](u pgtype.UUID) nilable.Value[T] {
 	str := T(id.String())
 	return nilable.NewValue(&str)
 }
+
+func ToUUID[T ~string](v Nullable[T]) (pgtype.UUID, error) {
+	return FromUUID(v.OrNil())
+}
+
+func FromUUID[T ~string](p *T) (pgtype.UUID, error) {
+	if p == nil {
+		return pgtype.UUID{}, nil
+	}
+
+	id, err := uuid.Parse(string(*p))
+	if err != nil {
+		return pgtype.UUID{}, err
+	}
+
+	return pgtype.UUID{Bytes: id, Valid: true}, nil
+}
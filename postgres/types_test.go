@@ -1,12 +1,17 @@
 package postgres
 
 import (
+	"math"
+	"math/big"
 	"net/netip"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jeremybower/go-common"
+	"github.com/jeremybower/go-common/nilable"
+	"github.com/jeremybower/go-common/optional"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,6 +27,18 @@ func TestRequiredArray(t *testing.T) {
 	assert.Panics(t, func() { RequiredArray(pgtype.Array[int]{}) })
 }
 
+func TestTryArray(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryArray(pgtype.Array[int]{Elements: []int{1, 2, 3}, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, v)
+
+	_, err = TryArray(pgtype.Array[int]{})
+	assert.ErrorIs(t, err, ErrUnexpectedNull)
+	assert.EqualError(t, err, "unexpected null: array")
+}
+
 func TestNilableArray(t *testing.T) {
 	t.Parallel()
 
@@ -39,6 +56,17 @@ func TestRequiredFlatArray(t *testing.T) {
 	assert.Panics(t, func() { RequiredFlatArray[int](nil) })
 }
 
+func TestTryFlatArray(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryFlatArray([]int{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, v)
+
+	_, err = TryFlatArray[int](nil)
+	assert.ErrorIs(t, err, ErrUnexpectedNull)
+}
+
 func TestNilableFlatArray(t *testing.T) {
 	t.Parallel()
 
@@ -53,6 +81,17 @@ func TestRequiredBool(t *testing.T) {
 	assert.Panics(t, func() { RequiredBool(pgtype.Bool{Valid: false}) })
 }
 
+func TestTryBool(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryBool(pgtype.Bool{Bool: true, Valid: true})
+	assert.NoError(t, err)
+	assert.True(t, v)
+
+	_, err = TryBool(pgtype.Bool{Valid: false})
+	assert.EqualError(t, err, "unexpected null: bool")
+}
+
 func TestNilableBool(t *testing.T) {
 	t.Parallel()
 
@@ -60,6 +99,79 @@ func TestNilableBool(t *testing.T) {
 	assert.True(t, *NilableBool(pgtype.Bool{Bool: true, Valid: true}).Value)
 }
 
+func TestToBool(t *testing.T) {
+	t.Parallel()
+
+	b := true
+	assert.Equal(t, pgtype.Bool{Bool: true, Valid: true}, FromBool(&b))
+	assert.Equal(t, pgtype.Bool{}, FromBool(nil))
+	assert.Equal(t, pgtype.Bool{Bool: true, Valid: true}, ToBool(optional.NewValue(true)))
+}
+
+func TestRequiredDate(t *testing.T) {
+	t.Parallel()
+
+	d, err := RequiredDate(pgtype.Date{Time: time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC), Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, common.NewDate(2024, 11, 22), d)
+
+	_, err = RequiredDate(pgtype.Date{InfinityModifier: pgtype.Infinity, Valid: true})
+	assert.ErrorIs(t, err, ErrInfiniteTime)
+
+	assert.Panics(t, func() { RequiredDate(pgtype.Date{Valid: false}) })
+}
+
+func TestTryDate(t *testing.T) {
+	t.Parallel()
+
+	d, err := TryDate(pgtype.Date{Time: time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC), Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, common.NewDate(2024, 11, 22), d)
+
+	_, err = TryDate(pgtype.Date{InfinityModifier: pgtype.Infinity, Valid: true})
+	assert.ErrorIs(t, err, ErrInfiniteTime)
+
+	_, err = TryDate(pgtype.Date{Valid: false})
+	assert.EqualError(t, err, "unexpected null: date")
+}
+
+func TestNilableDate(t *testing.T) {
+	t.Parallel()
+
+	v, err := NilableDate(pgtype.Date{Valid: false})
+	assert.NoError(t, err)
+	assert.True(t, v.Valid)
+	assert.Nil(t, v.Value)
+
+	v, err = NilableDate(pgtype.Date{Time: time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC), Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, common.NewDate(2024, 11, 22), *v.Value)
+
+	v, err = NilableDate(pgtype.Date{InfinityModifier: pgtype.NegativeInfinity, Valid: true})
+	assert.ErrorIs(t, err, ErrInfiniteTime)
+	assert.False(t, v.Valid)
+}
+
+func TestToDate(t *testing.T) {
+	t.Parallel()
+
+	d, err := ToDate(optional.NewValue(common.NewDate(2024, 11, 22)))
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.Date{Time: time.Date(2024, 11, 22, 0, 0, 0, 0, time.UTC), Valid: true}, d)
+
+	d, err = ToDate(nilable.NilValue[common.Date]())
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.Date{}, d)
+
+	d, err = FromDate(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.Date{}, d)
+
+	invalid := common.NewDate(2024, 2, 30)
+	_, err = FromDate(&invalid)
+	assert.ErrorIs(t, err, common.ErrInvalidDate)
+}
+
 func TestRequiredFloat4(t *testing.T) {
 	t.Parallel()
 
@@ -67,6 +179,17 @@ func TestRequiredFloat4(t *testing.T) {
 	assert.Panics(t, func() { RequiredFloat4[float32](pgtype.Float4{Valid: false}) })
 }
 
+func TestTryFloat4(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryFloat4[float32](pgtype.Float4{Float32: 1.5, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1.5), v)
+
+	_, err = TryFloat4[float32](pgtype.Float4{Valid: false})
+	assert.EqualError(t, err, "unexpected null: float4")
+}
+
 func TestNilableFloat4(t *testing.T) {
 	t.Parallel()
 
@@ -74,6 +197,14 @@ func TestNilableFloat4(t *testing.T) {
 	assert.Equal(t, float32(1.23), *NilableFloat4[float32](pgtype.Float4{Float32: 1.23, Valid: true}).Value)
 }
 
+func TestToFloat4(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, pgtype.Float4{Float32: 1.5, Valid: true}, ToFloat4(optional.NewValue[float32](1.5)))
+	assert.Equal(t, pgtype.Float4{}, ToFloat4(nilable.NilValue[float32]()))
+	assert.Equal(t, pgtype.Float4{}, FromFloat4[float32](nil))
+}
+
 func TestRequiredFloat8(t *testing.T) {
 	t.Parallel()
 
@@ -81,6 +212,17 @@ func TestRequiredFloat8(t *testing.T) {
 	assert.Panics(t, func() { RequiredFloat8[float64](pgtype.Float8{Valid: false}) })
 }
 
+func TestTryFloat8(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryFloat8[float64](pgtype.Float8{Float64: 1.5, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, v)
+
+	_, err = TryFloat8[float64](pgtype.Float8{Valid: false})
+	assert.EqualError(t, err, "unexpected null: float8")
+}
+
 func TestNilableFloat8(t *testing.T) {
 	t.Parallel()
 
@@ -88,6 +230,14 @@ func TestNilableFloat8(t *testing.T) {
 	assert.Equal(t, float64(1.23), *NilableFloat8[float64](pgtype.Float8{Float64: 1.23, Valid: true}).Value)
 }
 
+func TestToFloat8(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, pgtype.Float8{Float64: 1.5, Valid: true}, ToFloat8(optional.NewValue(1.5)))
+	assert.Equal(t, pgtype.Float8{}, ToFloat8(nilable.NilValue[float64]()))
+	assert.Equal(t, pgtype.Float8{}, FromFloat8[float64](nil))
+}
+
 func TestRequiredInt2(t *testing.T) {
 	t.Parallel()
 
@@ -95,6 +245,17 @@ func TestRequiredInt2(t *testing.T) {
 	assert.Panics(t, func() { RequiredInt2[int16](pgtype.Int2{Valid: false}) })
 }
 
+func TestTryInt2(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryInt2[int16](pgtype.Int2{Int16: 1, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int16(1), v)
+
+	_, err = TryInt2[int16](pgtype.Int2{Valid: false})
+	assert.EqualError(t, err, "unexpected null: int2")
+}
+
 func TestNilableInt2(t *testing.T) {
 	t.Parallel()
 
@@ -102,6 +263,14 @@ func TestNilableInt2(t *testing.T) {
 	assert.Equal(t, int16(123), *NilableInt2[int16](pgtype.Int2{Int16: 123, Valid: true}).Value)
 }
 
+func TestToInt2(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, pgtype.Int2{Int16: 1, Valid: true}, ToInt2(optional.NewValue[int16](1)))
+	assert.Equal(t, pgtype.Int2{}, ToInt2(nilable.NilValue[int16]()))
+	assert.Equal(t, pgtype.Int2{}, FromInt2[int16](nil))
+}
+
 func TestRequiredInt4(t *testing.T) {
 	t.Parallel()
 
@@ -112,6 +281,17 @@ func TestRequiredInt4(t *testing.T) {
 	assert.Panics(t, func() { RequiredInt4[int32](pgtype.Int4{Valid: false}) })
 }
 
+func TestTryInt4(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryInt4[int](pgtype.Int4{Int32: 1, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	_, err = TryInt4[int32](pgtype.Int4{Valid: false})
+	assert.EqualError(t, err, "unexpected null: int4")
+}
+
 func TestNilableInt4(t *testing.T) {
 	t.Parallel()
 
@@ -119,6 +299,27 @@ func TestNilableInt4(t *testing.T) {
 	assert.Equal(t, int32(123), *NilableInt4[int32](pgtype.Int4{Int32: 123, Valid: true}).Value)
 }
 
+func TestToInt4(t *testing.T) {
+	t.Parallel()
+
+	v, err := ToInt4(optional.NewValue[int32](1))
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.Int4{Int32: 1, Valid: true}, v)
+
+	n := 2
+	v, err = FromInt4(&n)
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.Int4{Int32: 2, Valid: true}, v)
+
+	v, err = ToInt4(nilable.NilValue[int32]())
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.Int4{}, v)
+
+	n = math.MaxInt32 + 1
+	_, err = FromInt4(&n)
+	assert.ErrorIs(t, err, ErrIntOverflow)
+}
+
 func TestRequiredInt8(t *testing.T) {
 	t.Parallel()
 
@@ -126,6 +327,17 @@ func TestRequiredInt8(t *testing.T) {
 	assert.Panics(t, func() { RequiredInt8[int64](pgtype.Int8{Valid: false}) })
 }
 
+func TestTryInt8(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryInt8[int64](pgtype.Int8{Int64: 1, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+
+	_, err = TryInt8[int64](pgtype.Int8{Valid: false})
+	assert.EqualError(t, err, "unexpected null: int8")
+}
+
 func TestNilableInt8(t *testing.T) {
 	t.Parallel()
 
@@ -133,6 +345,82 @@ func TestNilableInt8(t *testing.T) {
 	assert.Equal(t, int64(123), *NilableInt8[int64](pgtype.Int8{Int64: 123, Valid: true}).Value)
 }
 
+func TestToInt8(t *testing.T) {
+	t.Parallel()
+
+	type ID int64
+	assert.Equal(t, pgtype.Int8{Int64: 1, Valid: true}, ToInt8(optional.NewValue[ID](1)))
+	n := int64(2)
+	assert.Equal(t, pgtype.Int8{Int64: 2, Valid: true}, FromInt8(&n))
+	assert.Equal(t, pgtype.Int8{}, ToInt8(nilable.NilValue[int64]()))
+}
+
+func TestRequiredInterval(t *testing.T) {
+	t.Parallel()
+
+	d, err := RequiredInterval(pgtype.Interval{Days: 1, Microseconds: 1500, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour+1500*time.Microsecond, d)
+
+	d, err = RequiredInterval(pgtype.Interval{Days: -1, Microseconds: int64(time.Hour / time.Microsecond), Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, -23*time.Hour, d)
+
+	_, err = RequiredInterval(pgtype.Interval{Months: 1, Valid: true})
+	assert.ErrorIs(t, err, ErrIntervalMonths)
+
+	_, err = RequiredInterval(pgtype.Interval{Days: math.MaxInt32, Valid: true})
+	assert.ErrorIs(t, err, ErrIntervalOverflow)
+
+	_, err = RequiredInterval(pgtype.Interval{Microseconds: math.MaxInt64, Valid: true})
+	assert.ErrorIs(t, err, ErrIntervalOverflow)
+
+	_, err = RequiredInterval(pgtype.Interval{Days: 106751, Microseconds: int64(24 * time.Hour / time.Microsecond), Valid: true})
+	assert.ErrorIs(t, err, ErrIntervalOverflow)
+
+	assert.Panics(t, func() { RequiredInterval(pgtype.Interval{Valid: false}) })
+}
+
+func TestTryInterval(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryInterval(pgtype.Interval{Days: 1, Microseconds: 1, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour+time.Microsecond, v)
+
+	_, err = TryInterval(pgtype.Interval{Months: 1, Valid: true})
+	assert.ErrorIs(t, err, ErrIntervalMonths)
+
+	_, err = TryInterval(pgtype.Interval{Valid: false})
+	assert.EqualError(t, err, "unexpected null: interval")
+}
+
+func TestNilableInterval(t *testing.T) {
+	t.Parallel()
+
+	v, err := NilableInterval(pgtype.Interval{Valid: false})
+	assert.NoError(t, err)
+	assert.Nil(t, v.Value)
+
+	v, err = NilableInterval(pgtype.Interval{Microseconds: 1, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Microsecond, *v.Value)
+
+	v, err = NilableInterval(pgtype.Interval{Months: 1, Valid: true})
+	assert.ErrorIs(t, err, ErrIntervalMonths)
+	assert.False(t, v.Valid)
+}
+
+func TestToInterval(t *testing.T) {
+	t.Parallel()
+
+	d := 90 * time.Minute
+	assert.Equal(t, pgtype.Interval{Microseconds: 5_400_000_000, Valid: true}, FromInterval(&d))
+	assert.Equal(t, pgtype.Interval{}, FromInterval(nil))
+	assert.Equal(t, pgtype.Interval{Microseconds: 5_400_000_000, Valid: true}, ToInterval(nilable.NewValue(&d)))
+	assert.Equal(t, pgtype.Interval{}, ToInterval(optional.InvalidValue[time.Duration]()))
+}
+
 func TestRequiredIPAddr(t *testing.T) {
 	t.Parallel()
 
@@ -140,6 +428,18 @@ func TestRequiredIPAddr(t *testing.T) {
 	assert.Panics(t, func() { RequiredIPAddr(netip.Addr{}) })
 }
 
+func TestTryIPAddr(t *testing.T) {
+	t.Parallel()
+
+	ip := netip.MustParseAddr("127.0.0.1")
+	v, err := TryIPAddr(ip)
+	assert.NoError(t, err)
+	assert.Equal(t, ip, v)
+
+	_, err = TryIPAddr(netip.Addr{})
+	assert.EqualError(t, err, "unexpected null: inet")
+}
+
 func TestNilableIPAddr(t *testing.T) {
 	t.Parallel()
 
@@ -154,6 +454,17 @@ func TestRequiredJSON(t *testing.T) {
 	assert.Panics(t, func() { RequiredJSON(nil) })
 }
 
+func TestTryJSON(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryJSON(map[string]any{"a": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": 1}, v)
+
+	_, err = TryJSON(nil)
+	assert.EqualError(t, err, "unexpected null: json")
+}
+
 func TestNilableJSON(t *testing.T) {
 	t.Parallel()
 
@@ -161,6 +472,121 @@ func TestNilableJSON(t *testing.T) {
 	assert.Equal(t, map[string]any{"a": 1}, NilableJSON(map[string]any{"a": 1}).Map)
 }
 
+func TestRequiredJSONB(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []int{1, 2}, RequiredJSONB(NewJSONB([]int{1, 2})))
+	assert.Panics(t, func() { RequiredJSONB(JSONB[[]int]{}) })
+}
+
+func TestTryJSONB(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryJSONB(NewJSONB([]int{1, 2}))
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, v)
+
+	_, err = TryJSONB(JSONB[[]int]{})
+	assert.EqualError(t, err, "unexpected null: jsonb")
+}
+
+func TestNilableJSONB(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, NilableJSONB(JSONB[[]int]{}).Value)
+	assert.Equal(t, []int{1, 2}, *NilableJSONB(NewJSONB([]int{1, 2})).Value)
+}
+
+func TestOptionalJSONB(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, OptionalJSONB(JSONB[[]int]{}).Valid)
+	assert.Equal(t, optional.NewValue([]int{1, 2}), OptionalJSONB(NewJSONB([]int{1, 2})))
+}
+
+func TestRequiredNumeric(t *testing.T) {
+	t.Parallel()
+
+	n := pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true}
+
+	d, err := RequiredNumeric(n, ToDecimal)
+	assert.NoError(t, err)
+	assert.Equal(t, "123.45", d.String())
+
+	_, err = RequiredNumeric(pgtype.Numeric{NaN: true, Valid: true}, ToDecimal)
+	assert.ErrorIs(t, err, ErrNumericNaN)
+
+	_, err = RequiredNumeric(pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, ToDecimal)
+	assert.ErrorIs(t, err, ErrNumericInfinity)
+
+	assert.Panics(t, func() { _, _ = RequiredNumeric(pgtype.Numeric{}, ToDecimal) })
+}
+
+func TestTryNumeric(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryNumeric(pgtype.Numeric{Int: big.NewInt(150), Exp: -2, Valid: true}, ToDecimalString)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.50", v)
+
+	_, err = TryNumeric(pgtype.Numeric{NaN: true, Valid: true}, ToDecimal)
+	assert.ErrorIs(t, err, ErrNumericNaN)
+
+	_, err = TryNumeric(pgtype.Numeric{}, ToDecimal)
+	assert.EqualError(t, err, "unexpected null: numeric")
+}
+
+func TestNilableNumeric(t *testing.T) {
+	t.Parallel()
+
+	v, err := NilableNumeric(pgtype.Numeric{}, ToRat)
+	assert.NoError(t, err)
+	assert.True(t, v.Valid)
+	assert.Nil(t, v.Value)
+
+	v, err = NilableNumeric(pgtype.Numeric{Int: big.NewInt(15), Exp: -1, Valid: true}, ToRat)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(3, 2), *v.Value)
+
+	v, err = NilableNumeric(pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, ToRat)
+	assert.ErrorIs(t, err, ErrNumericInfinity)
+	assert.False(t, v.Valid)
+}
+
+func TestToNumeric(t *testing.T) {
+	t.Parallel()
+
+	n := pgtype.Numeric{Int: big.NewInt(12), Exp: 2, Valid: true}
+
+	s, err := ToDecimalString(n)
+	assert.NoError(t, err)
+	assert.Equal(t, "1200", s)
+
+	r, err := ToRat(n)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewRat(1200, 1), r)
+
+	i, err := ToBigInt(n)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(1200), i)
+
+	_, err = ToBigInt(pgtype.Numeric{Int: big.NewInt(12), Exp: -1, Valid: true})
+	assert.ErrorIs(t, err, ErrNumericNotInteger)
+
+	_, err = ToDecimalString(pgtype.Numeric{NaN: true, Valid: true})
+	assert.ErrorIs(t, err, ErrNumericNaN)
+
+	_, err = ToRat(pgtype.Numeric{InfinityModifier: pgtype.NegativeInfinity, Valid: true})
+	assert.ErrorIs(t, err, ErrNumericInfinity)
+
+	_, err = ToDecimal(pgtype.Numeric{})
+	assert.ErrorIs(t, err, ErrNumeric)
+
+	d, err := ToDecimal(pgtype.Numeric{Exp: -2, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "0.00", d.String())
+}
+
 func TestRequiredPoint(t *testing.T) {
 	t.Parallel()
 
@@ -168,6 +594,17 @@ func TestRequiredPoint(t *testing.T) {
 	assert.Panics(t, func() { RequiredPoint(pgtype.Point{}, ToVec2) })
 }
 
+func TestTryPoint(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryPoint(pgtype.Point{P: pgtype.Vec2{X: 1, Y: 2}, Valid: true}, ToVec2)
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.Vec2{X: 1, Y: 2}, v)
+
+	_, err = TryPoint(pgtype.Point{}, ToVec2)
+	assert.EqualError(t, err, "unexpected null: point")
+}
+
 func TestNilablePoint(t *testing.T) {
 	t.Parallel()
 
@@ -175,6 +612,54 @@ func TestNilablePoint(t *testing.T) {
 	assert.Equal(t, pgtype.Vec2{X: 1.23, Y: 4.56}, *NilablePoint(pgtype.Point{P: pgtype.Vec2{X: 1.23, Y: 4.56}, Valid: true}, ToVec2).Value)
 }
 
+func TestRequiredRange(t *testing.T) {
+	t.Parallel()
+
+	r := pgtype.Range[pgtype.Int4]{
+		Lower:     pgtype.Int4{Int32: 1, Valid: true},
+		LowerType: pgtype.Inclusive,
+		UpperType: pgtype.Unbounded,
+		Valid:     true,
+	}
+
+	assert.Equal(t, NewRange[int32](1, 0, Inclusive, Unbounded), RequiredRange(r, RequiredInt4[int32]))
+	assert.Equal(t, EmptyRange[int32](), RequiredRange(pgtype.Range[pgtype.Int4]{LowerType: pgtype.Empty, UpperType: pgtype.Empty, Valid: true}, RequiredInt4[int32]))
+	assert.Panics(t, func() { RequiredRange(pgtype.Range[pgtype.Int4]{}, RequiredInt4[int32]) })
+}
+
+func TestTryRange(t *testing.T) {
+	t.Parallel()
+
+	identity := func(v int32) int32 { return v }
+	v, err := TryRange(pgtype.Range[int32]{
+		Lower:     1,
+		Upper:     5,
+		LowerType: pgtype.Inclusive,
+		UpperType: pgtype.Exclusive,
+		Valid:     true,
+	}, identity)
+	assert.NoError(t, err)
+	assert.Equal(t, NewRange[int32](1, 5, Inclusive, Exclusive), v)
+
+	_, err = TryRange(pgtype.Range[int32]{}, identity)
+	assert.EqualError(t, err, "unexpected null: range")
+}
+
+func TestNilableRange(t *testing.T) {
+	t.Parallel()
+
+	r := pgtype.Range[pgtype.Int8]{
+		Lower:     pgtype.Int8{Int64: 1, Valid: true},
+		Upper:     pgtype.Int8{Int64: 5, Valid: true},
+		LowerType: pgtype.Exclusive,
+		UpperType: pgtype.Inclusive,
+		Valid:     true,
+	}
+
+	assert.Nil(t, NilableRange(pgtype.Range[pgtype.Int8]{}, RequiredInt8[int64]).Value)
+	assert.Equal(t, NewRange[int64](1, 5, Exclusive, Inclusive), *NilableRange(r, RequiredInt8[int64]).Value)
+}
+
 func TestRequiredText(t *testing.T) {
 	t.Parallel()
 
@@ -182,6 +667,17 @@ func TestRequiredText(t *testing.T) {
 	assert.Panics(t, func() { RequiredText[string](pgtype.Text{Valid: false}) })
 }
 
+func TestTryText(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryText[string](pgtype.Text{String: "hello", Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", v)
+
+	_, err = TryText[string](pgtype.Text{Valid: false})
+	assert.EqualError(t, err, "unexpected null: text")
+}
+
 func TestNilableText(t *testing.T) {
 	t.Parallel()
 
@@ -189,6 +685,49 @@ func TestNilableText(t *testing.T) {
 	assert.Equal(t, "abc", *NilableText[string](pgtype.Text{String: "abc", Valid: true}).Value)
 }
 
+func TestToText(t *testing.T) {
+	t.Parallel()
+
+	type Name string
+	s := Name("hello")
+	assert.Equal(t, pgtype.Text{String: "hello", Valid: true}, FromText(&s))
+	assert.Equal(t, pgtype.Text{}, ToText(nilable.NilValue[Name]()))
+	assert.Equal(t, pgtype.Text{String: "hello", Valid: true}, ToText(optional.NewValue(s)))
+	assert.Equal(t, pgtype.Text{}, FromText[Name](nil))
+}
+
+func TestRequiredTime(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 13*time.Hour+30*time.Minute, RequiredTime(pgtype.Time{Microseconds: int64((13*time.Hour + 30*time.Minute) / time.Microsecond), Valid: true}))
+	assert.Panics(t, func() { RequiredTime(pgtype.Time{Valid: false}) })
+}
+
+func TestTryTime(t *testing.T) {
+	t.Parallel()
+
+	v, err := TryTime(pgtype.Time{Microseconds: int64(time.Hour / time.Microsecond), Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, v)
+
+	_, err = TryTime(pgtype.Time{Valid: false})
+	assert.EqualError(t, err, "unexpected null: time")
+}
+
+func TestNilableTime(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, NilableTime(pgtype.Time{Valid: false}).Value)
+	assert.Equal(t, time.Second, *NilableTime(pgtype.Time{Microseconds: 1000000, Valid: true}).Value)
+}
+
+func TestToTime(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, pgtype.Time{Microseconds: 3_600_000_000, Valid: true}, ToTime(optional.NewValue(time.Hour)))
+	assert.Equal(t, pgtype.Time{}, FromTime(nil))
+}
+
 func TestRequiredTimestamp(t *testing.T) {
 	t.Parallel()
 
@@ -196,6 +735,18 @@ func TestRequiredTimestamp(t *testing.T) {
 	assert.Panics(t, func() { RequiredTimestamp(pgtype.Timestamp{Valid: false}) })
 }
 
+func TestTryTimestamp(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	v, err := TryTimestamp(pgtype.Timestamp{Time: now, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, now, v)
+
+	_, err = TryTimestamp(pgtype.Timestamp{Valid: false})
+	assert.EqualError(t, err, "unexpected null: timestamp")
+}
+
 func TestNilableTimestamp(t *testing.T) {
 	t.Parallel()
 
@@ -203,6 +754,67 @@ func TestNilableTimestamp(t *testing.T) {
 	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), *NilableTimestamp(pgtype.Timestamp{Time: time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC), Valid: true}).Value)
 }
 
+func TestToTimestamp(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	assert.Equal(t, pgtype.Timestamp{Time: now.Truncate(TimePrecision), Valid: true}, ToTimestamp(optional.NewValue(now)))
+	assert.Equal(t, pgtype.Timestamp{}, FromTimestamp(nil))
+}
+
+func TestRequiredTimestamptz(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("test", 3600)
+	v, err := RequiredTimestamptz(pgtype.Timestamptz{Time: time.Date(2024, 1, 2, 3, 4, 5, 6789, loc), Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 2, 4, 5, 6000, time.UTC), v)
+
+	_, err = RequiredTimestamptz(pgtype.Timestamptz{InfinityModifier: pgtype.Infinity, Valid: true})
+	assert.ErrorIs(t, err, ErrInfiniteTime)
+
+	assert.Panics(t, func() { RequiredTimestamptz(pgtype.Timestamptz{Valid: false}) })
+}
+
+func TestTryTimestamptz(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	v, err := TryTimestamptz(pgtype.Timestamptz{Time: now, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, Time(now), v)
+
+	_, err = TryTimestamptz(pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true})
+	assert.ErrorIs(t, err, ErrInfiniteTime)
+
+	_, err = TryTimestamptz(pgtype.Timestamptz{Valid: false})
+	assert.EqualError(t, err, "unexpected null: timestamptz")
+}
+
+func TestNilableTimestamptz(t *testing.T) {
+	t.Parallel()
+
+	v, err := NilableTimestamptz(pgtype.Timestamptz{Valid: false})
+	assert.NoError(t, err)
+	assert.Nil(t, v.Value)
+
+	v, err = NilableTimestamptz(pgtype.Timestamptz{Time: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), *v.Value)
+
+	v, err = NilableTimestamptz(pgtype.Timestamptz{InfinityModifier: pgtype.NegativeInfinity, Valid: true})
+	assert.ErrorIs(t, err, ErrInfiniteTime)
+	assert.False(t, v.Valid)
+}
+
+func TestToTimestamptz(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	assert.Equal(t, pgtype.Timestamptz{Time: Time(now), Valid: true}, ToTimestamptz(optional.NewValue(now)))
+	assert.Equal(t, pgtype.Timestamptz{}, FromTimestamptz(nil))
+}
+
 func TestRequiredUUID(t *testing.T) {
 	t.Parallel()
 
@@ -212,6 +824,18 @@ func TestRequiredUUID(t *testing.T) {
 	assert.Panics(t, func() { RequiredUUID[string](pgtype.UUID{Valid: false}) })
 }
 
+func TestTryUUID(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	v, err := TryUUID[string](pgtype.UUID{Bytes: id, Valid: true})
+	assert.NoError(t, err)
+	assert.Equal(t, id.String(), v)
+
+	_, err = TryUUID[string](pgtype.UUID{Valid: false})
+	assert.EqualError(t, err, "unexpected null: uuid")
+}
+
 func TestNilableUUID(t *testing.T) {
 	t.Parallel()
 
@@ -220,3 +844,33 @@ func TestNilableUUID(t *testing.T) {
 	assert.Nil(t, NilableUUID[string](pgtype.UUID{Valid: false}).Value)
 	assert.Equal(t, id.String(), *NilableUUID[string](pgtype.UUID{Bytes: id, Valid: true}).Value)
 }
+
+func TestToUUID(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	u, err := ToUUID(optional.NewValue(id.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.UUID{Bytes: id, Valid: true}, u)
+
+	str := id.String()
+	u, err = FromUUID(&str)
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.UUID{Bytes: id, Valid: true}, u)
+
+	u, err = ToUUID(nilable.NilValue[string]())
+	assert.NoError(t, err)
+	assert.Equal(t, pgtype.UUID{}, u)
+
+	_, err = ToUUID(optional.NewValue("not-a-uuid"))
+	assert.Error(t, err)
+}
+
+func TestToAny(t *testing.T) {
+	t.Parallel()
+
+	s := "hello"
+	assert.Equal(t, "hello", FromAny(&s))
+	assert.Nil(t, ToAny(nilable.NilValue[string]()))
+	assert.Nil(t, ToAny(optional.InvalidValue[string]()))
+}